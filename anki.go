@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// runExportAnki implements the "export-anki" subcommand.
+//
+// It writes the questions of a quiz as a tab-separated file that Anki's
+// "Import File" command reads as a deck of Basic notes, with each question's
+// category as a tag.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, the quiz cannot be loaded,
+//     or the deck cannot be written.
+func runExportAnki(ctx context.Context, args []string) error {
+	flags := newFlagSet("export-anki")
+	lang := flags.String("lang", "", "export the question_<lang> and answer_<lang> columns")
+	output := flags.String("o", "", "write the deck to `file` instead of standard output")
+	tags := flags.String("tags", "", "space-separated `tags` added to every note")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageErrorf("usage: go-quiz export-anki [-lang language] [-tags tags] [-o file] quiz.csv")
+	}
+
+	questions, _, err := loadQuiz(flags.Arg(0), loadOptions{Lang: *lang}, slog.Warn)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("error creating output: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := writeAnkiDeck(w, questions, strings.Fields(*tags)); err != nil {
+		return err
+	}
+
+	if *output != "" {
+		notice(fmt.Sprintf("Exported %d notes to %s", len(questions), *output), "file", *output)
+	}
+	return nil
+}
+
+// writeAnkiDeck writes questions in Anki's plain-text import format.
+//
+// The header lines tell Anki the file is tab-separated plain text with tags in
+// the third column, so it imports without any options being changed. The
+// answer includes the romanization, when there is one, in parentheses.
+//
+// Parameters:
+//   - w: where to write the deck.
+//   - questions: the questions to export, one note each.
+//   - tags: extra tags for every note.
+//
+// Returns:
+//   - error: an error if the deck cannot be written.
+func writeAnkiDeck(w io.Writer, questions []question, tags []string) error {
+	fmt.Fprintln(w, "#separator:tab")
+	fmt.Fprintln(w, "#html:false")
+	fmt.Fprintln(w, "#tags column:3")
+
+	writer := csv.NewWriter(w)
+	writer.Comma = '\t'
+
+	for _, q := range questions {
+		back := q.Answer
+		if q.Romanization != "" {
+			back += " (" + q.Romanization + ")"
+		}
+
+		noteTags := tags
+		if q.Category != "" {
+			// Anki tags cannot contain spaces.
+			noteTags = append(append([]string(nil), tags...), strings.Join(strings.Fields(q.Category), "_"))
+		}
+
+		if err := writer.Write([]string{q.Text, back, strings.Join(noteTags, " ")}); err != nil {
+			return fmt.Errorf("error writing deck: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing deck: %w", err)
+	}
+	return nil
+}
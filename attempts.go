@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// aggregations combine the attempts of one user at a quiz into the attempt
+// that counts, e.g. for grading. The attempts are in the order they were made.
+var aggregations = map[string]func(attempts []historyEntry) historyEntry{
+	"latest": func(attempts []historyEntry) historyEntry {
+		return attempts[len(attempts)-1]
+	},
+	"best": func(attempts []historyEntry) historyEntry {
+		// The earliest of equally good attempts counts.
+		best := attempts[0]
+		for _, e := range attempts[1:] {
+			if e.Score > best.Score {
+				best = e
+			}
+		}
+		return best
+	},
+	"average": averageAttempt,
+}
+
+// averageAttempt returns the latest attempt with its score replaced by the
+// mean score of all attempts, and the number of correct answers by the mean
+// rounded to the nearest whole answer.
+func averageAttempt(attempts []historyEntry) historyEntry {
+	var correct, score float64
+	for _, e := range attempts {
+		correct += float64(e.Correct)
+		score += e.Score
+	}
+	n := float64(len(attempts))
+
+	avg := attempts[len(attempts)-1]
+	avg.Correct = int(math.Round(correct / n))
+	avg.Score = score / n
+	avg.Categories = nil
+	avg.Questions = nil
+	return avg
+}
+
+// aggregationNames returns the names of the aggregations for usage messages, e.g. "average, best or latest".
+func aggregationNames() string {
+	names := slices.Sorted(maps.Keys(aggregations))
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// aggregatePerUser combines the attempts of each user into one entry.
+// Attempts anonymized by prune carry no user, so they are left out rather
+// than combined as if they were one student's.
+//
+// Parameters:
+//   - entries: the attempts at a quiz, oldest first.
+//   - how: the name of the aggregation, a key of aggregations.
+//
+// Returns:
+//   - []historyEntry: one entry per user, ordered by when each user first appears in the history.
+//   - []int: the number of attempts of each user.
+//   - error: an error if the aggregation is unknown.
+func aggregatePerUser(entries []historyEntry, how string) ([]historyEntry, []int, error) {
+	aggregate, ok := aggregations[how]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown aggregation %q: use %s", how, aggregationNames())
+	}
+
+	var users []string
+	attempts := make(map[string][]historyEntry)
+	for _, e := range entries {
+		if e.User == "" {
+			continue
+		}
+		if _, seen := attempts[e.User]; !seen {
+			users = append(users, e.User)
+		}
+		attempts[e.User] = append(attempts[e.User], e)
+	}
+
+	combined := make([]historyEntry, len(users))
+	counts := make([]int, len(users))
+	for i, u := range users {
+		combined[i] = aggregate(attempts[u])
+		counts[i] = len(attempts[u])
+	}
+	return combined, counts, nil
+}
+
+// checkAttemptLimit reports an error if the current user has already made
+// the maximum number of attempts at a quiz. The quiz's own max-attempts
+// setting applies if it is stricter than limit, so commands taking
+// questions from several quizzes respect each one's.
+//
+// Parameters:
+//   - ctx: the context of the request to a storage server, if one is used.
+//   - filePath: the path to the quiz file.
+//   - limit: the number of attempts allowed by -max-attempts; 0 or less
+//     allows any number.
+func checkAttemptLimit(ctx context.Context, filePath string, limit int) error {
+	quiz, err := filepath.Abs(filePath)
+	if err != nil {
+		return fmt.Errorf("error expanding path: %w", err)
+	}
+	settings, err := readQuizSettings(quiz)
+	if err != nil {
+		return err
+	}
+	if set, ok := settings.Defaults["max-attempts"]; ok {
+		if loosensLimit(strconv.Itoa(limit), set) {
+			if limit, err = strconv.Atoi(set); err != nil {
+				return asParseError(quiz, fmt.Errorf("invalid value %q for the max-attempts setting in the quiz file: %w", set, err))
+			}
+		}
+	}
+	if limit <= 0 {
+		return nil
+	}
+	entries, err := loadHistory(ctx, quiz)
+	if err != nil {
+		return err
+	}
+
+	user := currentUser()
+	made := 0
+	for _, e := range entries {
+		if e.User == user {
+			made++
+		}
+	}
+	if made >= limit {
+		noun := "attempts"
+		if limit == 1 {
+			noun = "attempt"
+		}
+		return fmt.Errorf("you have used all %d %s allowed at this quiz", limit, noun)
+	}
+	return nil
+}
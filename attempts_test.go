@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAggregatePerUser(t *testing.T) {
+	entries := []historyEntry{
+		{User: "alice", Score: 50},
+		{User: "", Score: 90},
+		{User: "bob", Score: 70},
+		{User: "alice", Score: 80},
+		{User: "", Score: 10},
+	}
+	tests := []struct {
+		how    string
+		users  []string
+		scores []float64
+		counts []int
+	}{
+		{how: "latest", users: []string{"alice", "bob"}, scores: []float64{80, 70}, counts: []int{2, 1}},
+		{how: "best", users: []string{"alice", "bob"}, scores: []float64{80, 70}, counts: []int{2, 1}},
+		{how: "average", users: []string{"alice", "bob"}, scores: []float64{65, 70}, counts: []int{2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.how, func(t *testing.T) {
+			combined, counts, err := aggregatePerUser(entries, tt.how)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var users []string
+			var scores []float64
+			for _, e := range combined {
+				users = append(users, e.User)
+				scores = append(scores, e.Score)
+			}
+			if !slices.Equal(users, tt.users) || !slices.Equal(scores, tt.scores) || !slices.Equal(counts, tt.counts) {
+				t.Errorf("aggregatePerUser = %v %v %v, want %v %v %v", users, scores, counts, tt.users, tt.scores, tt.counts)
+			}
+		})
+	}
+
+	if _, _, err := aggregatePerUser(entries, "median"); err == nil {
+		t.Error("aggregatePerUser with an unknown aggregation succeeded, want an error")
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// regenerableState lists the state subdirectories left out of backups. They
+// only hold caches keyed by the quiz files' absolute paths, which are rebuilt
+// on demand and would not match on another machine anyway.
+var regenerableState = []string{"cache", "index"}
+
+// runBackup implements the "backup" subcommand.
+//
+// It bundles the state directory, including the attempt history, into a
+// gzip-compressed tar archive that restore can unpack on another machine.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the archive cannot be written.
+func runBackup(ctx context.Context, args []string) error {
+	flags := newFlagSet("backup")
+	output := flags.String("o", "go-quiz-backup-"+time.Now().Format("20060102")+".tar.gz", "write the archive to `file`")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return usageErrorf("usage: go-quiz backup [-o file]")
+	}
+
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("error creating archive: %w", err)
+	}
+	defer file.Close()
+
+	count, err := writeBackup(file, dir)
+	if err != nil {
+		os.Remove(*output)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing archive: %w", err)
+	}
+
+	fmt.Printf("Backed up %d files from %s to %s\n", count, dir, *output)
+	return nil
+}
+
+// writeBackup writes the files in dir as a gzip-compressed tar archive to w,
+// skipping the regenerable subdirectories. It returns the number of files written.
+// A missing state directory produces an empty archive.
+func writeBackup(w io.Writer, dir string) (int, error) {
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	count := 0
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if entry.IsDir() {
+			if slices.Contains(regenerableState, rel) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		source, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+		if _, err := io.Copy(archive, source); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error archiving state: %w", err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return 0, fmt.Errorf("error writing archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return 0, fmt.Errorf("error writing archive: %w", err)
+	}
+	return count, nil
+}
+
+// runRestore implements the "restore" subcommand.
+//
+// It unpacks an archive made by backup into the state directory. Existing
+// files are kept, and the restore aborted before anything is written, unless
+// -force is given.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, the archive is invalid,
+//     or it would overwrite existing state without -force.
+func runRestore(ctx context.Context, args []string) error {
+	flags := newFlagSet("restore")
+	force := flags.Bool("force", false, "overwrite existing state files")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageErrorf("usage: go-quiz restore [-force] backup.tar.gz")
+	}
+
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+
+	// Check every entry before writing anything, so a conflict leaves the state untouched.
+	names, err := scanBackup(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if !*force {
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return fmt.Errorf("%s already exists; use -force to overwrite it", filepath.Join(dir, name))
+			}
+		}
+	}
+
+	count, err := extractBackup(flags.Arg(0), dir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored %d files to %s\n", count, dir)
+	return nil
+}
+
+// openBackup opens a backup archive and returns a tar reader over it and a function closing it.
+func openBackup(path string) (*tar.Reader, func(), error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening archive: %w", err)
+	}
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("error reading archive: %w", err)
+	}
+	return tar.NewReader(gz), func() { gz.Close(); file.Close() }, nil
+}
+
+// scanBackup returns the names of the files in a backup archive, as paths
+// relative to the state directory, rejecting entries that would land outside it.
+func scanBackup(path string) ([]string, error) {
+	archive, closeArchive, err := openBackup(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeArchive()
+
+	var names []string
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return nil, fmt.Errorf("invalid path %q in archive", header.Name)
+		}
+		names = append(names, name)
+	}
+}
+
+// extractBackup writes the regular files of a backup archive into dir and
+// returns how many were written. Each file is written to a temporary file
+// first and renamed into place.
+func extractBackup(path, dir string) (int, error) {
+	archive, closeArchive, err := openBackup(path)
+	if err != nil {
+		return 0, err
+	}
+	defer closeArchive()
+
+	count := 0
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, fmt.Errorf("error reading archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return count, fmt.Errorf("invalid path %q in archive", header.Name)
+		}
+		if err := restoreFile(archive, filepath.Join(dir, name)); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+// restoreFile writes the contents of r to target, creating its directory if needed.
+func restoreFile(r io.Reader, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("error creating state directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "*.tmp")
+	if err != nil {
+		return fmt.Errorf("error restoring %s: %w", target, err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		return fmt.Errorf("error restoring %s: %w", target, err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		return fmt.Errorf("error restoring %s: %w", target, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error restoring %s: %w", target, err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("error restoring %s: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// optionalTextColumns are the columns whose blank cells bank-stats reports,
+// as questions that could do with one.
+var optionalTextColumns = []string{"hint", "explanation"}
+
+// noCategory stands for the category of questions without one.
+const noCategory = "(none)"
+
+// Question types counted by bank-stats.
+const (
+	typeMultipleChoice = "multiple choice"
+	typeNumeric        = "numeric"
+	typeFreeText       = "free text"
+)
+
+// questionType returns the kind of answer a question expects.
+func questionType(q question) string {
+	switch {
+	case len(q.Choices) > 0:
+		return typeMultipleChoice
+	case len(q.Tolerance) > 0 || isNumber(q.Answer):
+		return typeNumeric
+	default:
+		return typeFreeText
+	}
+}
+
+// difficultyBand returns how hard a calibrated question is: easy when at most
+// a third of answers were wrong, hard when more than two thirds were.
+func difficultyBand(q question) string {
+	switch {
+	case !q.Calibrated:
+		return "uncalibrated"
+	case q.Difficulty <= 1.0/3:
+		return "easy"
+	case q.Difficulty <= 2.0/3:
+		return "medium"
+	default:
+		return "hard"
+	}
+}
+
+// bankStats summarises the questions of a quiz file.
+type bankStats struct {
+	Questions int
+	// Categories, Difficulties and Types count the questions of each, in order of first appearance.
+	Categories   tally
+	Difficulties tally
+	Types        tally
+	// AverageRunes and AverageWords are the mean length of the question texts.
+	AverageRunes float64
+	AverageWords float64
+	// Blank counts the questions with a blank cell in each optionalTextColumns
+	// column the file has; absent columns are left out.
+	Blank map[string]int
+	// Notes counts the questions with author notes (see question.Notes).
+	Notes int
+}
+
+// tally counts occurrences of names, remembering the order they first appear in.
+type tally struct {
+	Names  []string
+	Counts map[string]int
+}
+
+// add counts one occurrence of name.
+func (t *tally) add(name string) {
+	if t.Counts == nil {
+		t.Counts = make(map[string]int)
+	}
+	if _, seen := t.Counts[name]; !seen {
+		t.Names = append(t.Names, name)
+	}
+	t.Counts[name]++
+}
+
+// collectBankStats summarises a quiz file's questions.
+//
+// Parameters:
+//   - records: the rows of the quiz file, excluding the header row.
+//   - headers: the header row.
+//   - questions: the questions parsed from the rows, in the same order.
+func collectBankStats(records [][]string, headers []string, questions []question) bankStats {
+	s := bankStats{Questions: len(questions), Blank: make(map[string]int)}
+	var runes, words int
+	for _, q := range questions {
+		category := q.Category
+		if category == "" {
+			category = noCategory
+		}
+		s.Categories.add(category)
+		s.Difficulties.add(difficultyBand(q))
+		s.Types.add(questionType(q))
+		runes += utf8.RuneCountInString(q.Text)
+		words += len(strings.Fields(q.Text))
+		if q.Notes != "" {
+			s.Notes++
+		}
+	}
+	if len(questions) > 0 {
+		s.AverageRunes = float64(runes) / float64(len(questions))
+		s.AverageWords = float64(words) / float64(len(questions))
+	}
+
+	for _, name := range optionalTextColumns {
+		col := columnIndex(headers, name)
+		if col < 0 {
+			continue
+		}
+		s.Blank[name] = 0
+		for _, row := range records {
+			if strings.TrimSpace(row[col]) == "" {
+				s.Blank[name]++
+			}
+		}
+	}
+	return s
+}
+
+// write prints the summary, flagging categories with fewer than minPerCategory questions.
+func (s bankStats) write(w io.Writer, minPerCategory int) {
+	fmt.Fprintf(w, "Questions: %d\n", s.Questions)
+	fmt.Fprintf(w, "Average question length: %.0f characters, %.1f words\n", s.AverageRunes, s.AverageWords)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, group := range []struct {
+		title string
+		t     tally
+		// flagFew flags the entries with too few questions.
+		flagFew bool
+	}{{"By category:", s.Categories, true}, {"By difficulty:", s.Difficulties, false}, {"By type:", s.Types, false}} {
+		fmt.Fprintln(tw, group.title)
+		for _, name := range group.t.Names {
+			n := group.t.Counts[name]
+			note := ""
+			if group.flagFew && name != noCategory && n < minPerCategory {
+				note = "\ttoo few"
+			}
+			fmt.Fprintf(tw, "  %s\t%d%s\n", name, n, note)
+		}
+	}
+	tw.Flush()
+
+	for _, name := range optionalTextColumns {
+		blank, ok := s.Blank[name]
+		if !ok {
+			fmt.Fprintf(w, "No %s column.\n", name)
+			continue
+		}
+		fmt.Fprintf(w, "Missing %s: %d of %d questions\n", name, blank, s.Questions)
+	}
+	fmt.Fprintf(w, "With author notes: %d of %d questions\n", s.Notes, s.Questions)
+}
+
+// runBankStats implements the "bank-stats" subcommand.
+//
+// It summarises the questions of a quiz file, to judge its coverage: how
+// many there are per category, difficulty and type, how long they are, how
+// many lack a hint or explanation, how many carry author notes, and which
+// categories have too few.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the quiz cannot be read.
+func runBankStats(ctx context.Context, args []string) error {
+	flags := newFlagSet("bank-stats")
+	minPerCategory := flags.Int("min-per-category", 5, "flag categories with fewer than `n` questions")
+	lang := flags.String("lang", "", "summarise the question_<lang> and answer_<lang> columns")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageErrorf("usage: go-quiz bank-stats [-min-per-category n] [-lang code] quiz.csv")
+	}
+
+	quiz, err := filepath.Abs(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error expanding path: %w", err)
+	}
+	records, headers, _, err := readQuizRecords(quiz)
+	if err != nil {
+		return err
+	}
+	questions, err := parseQuestions(records, headers, *lang)
+	if err != nil {
+		return err
+	}
+
+	collectBankStats(records, headers, questions).write(os.Stdout, *minPerCategory)
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// runBench implements the "bench" subcommand.
+//
+// It measures how long a quiz file takes to load, how much memory loading
+// allocates, and how many answers per second can be scored.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the quiz cannot be loaded.
+func runBench(ctx context.Context, args []string) error {
+	flags := newFlagSet("bench")
+	runs := flags.Int("n", 10, "number of times to load the file")
+	duration := flags.Duration("score-time", time.Second, "how long to run the scoring benchmark")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *runs < 1 {
+		return usageErrorf("usage: go-quiz bench [-n runs] [-score-time duration] quiz.csv")
+	}
+	filePath := flags.Arg(0)
+
+	var questions []question
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	start := time.Now()
+	for range *runs {
+		records, headers, _, err := readCSV(filePath, false)
+		if err != nil {
+			return err
+		}
+		questions, err = parseQuestions(records, headers, "")
+		if err != nil {
+			return err
+		}
+	}
+	parseTime := time.Since(start) / time.Duration(*runs)
+
+	runtime.ReadMemStats(&after)
+	allocated := (after.TotalAlloc - before.TotalAlloc) / uint64(*runs)
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	retained := int64(after.HeapAlloc) - int64(before.HeapAlloc)
+
+	fmt.Printf("Questions:        %d\n", len(questions))
+	fmt.Printf("Parse time:       %v per load (%d loads)\n", parseTime, *runs)
+	fmt.Printf("Allocated:        %s per load\n", formatBytes(int64(allocated)))
+	fmt.Printf("Retained heap:    %s\n", formatBytes(retained))
+
+	if len(questions) == 0 {
+		return nil
+	}
+
+	// Score every question with its own correct answer, as the worst case for matching.
+	responses := make([]response, 0, len(questions))
+	for _, q := range questions {
+		responses = append(responses, response{Question: q, Answer: q.Answer})
+	}
+
+	scored := 0
+	start = time.Now()
+	for time.Since(start) < *duration {
+		calculateScore(responses)
+		scored += len(responses)
+	}
+	throughput := float64(scored) / time.Since(start).Seconds()
+
+	fmt.Printf("Scoring:          %.0f answers/s\n", throughput)
+	return nil
+}
+
+// formatBytes renders a byte count using binary units, e.g. "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value := float64(n)
+	suffixes := []string{"KiB", "MiB", "GiB", "TiB"}
+	i := -1
+	for (value >= unit || value <= -unit) && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, suffixes[i])
+}
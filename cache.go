@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// cacheMinSize is the smallest quiz file worth caching; smaller files parse
+// faster than a cache lookup.
+const cacheMinSize = 1 << 20
+
+// quizCache is the parsed form of a quiz file as stored in the cache.
+type quizCache struct {
+	Size    int64
+	ModTime int64
+	Hash    [sha256.Size]byte
+	Headers []string
+	Records [][]string
+	Skipped []rowError
+}
+
+// readCachedCSV reads a CSV file like readCSV, reusing a parsed copy from
+// the state directory when the file has not changed.
+//
+// A cached copy is used directly when the file's size and modification time
+// match. Otherwise the file's content hash is compared, so touching a file
+// without changing it doesn't force it to be parsed again.
+//
+// Parameters:
+//   - filePath: a string representing the path to the CSV file to be read.
+//   - strict: whether a malformed row is an error instead of being skipped.
+//
+// Returns:
+//   - [][]string: the rows of the CSV file, excluding the header row.
+//   - []string: the header row.
+//   - []rowError: the malformed rows that were skipped; always empty in strict mode.
+//   - error: an error if the file cannot be read or parsed.
+//
+// Note:
+//   - Files smaller than cacheMinSize are always parsed directly.
+//   - Caching is best-effort: a cache that cannot be read or written is ignored.
+func readCachedCSV(filePath string, strict bool) ([][]string, []string, []rowError, error) {
+	info, err := os.Stat(filePath)
+	if err != nil || info.Size() < cacheMinSize {
+		return readCSV(filePath, strict)
+	}
+
+	path, err := quizStatePath("cache", filePath, ".gob")
+	if err != nil {
+		return readCSV(filePath, strict)
+	}
+
+	cached, cacheErr := loadQuizCache(path)
+	if cacheErr == nil && cached.Size == info.Size() && cached.ModTime == info.ModTime().UnixNano() {
+		return cachedResult(cached, strict)
+	}
+
+	hash, err := hashFile(filePath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if cacheErr == nil && cached.Hash == hash {
+		cached.Size = info.Size()
+		cached.ModTime = info.ModTime().UnixNano()
+		saveQuizCache(path, cached)
+		return cachedResult(cached, strict)
+	}
+
+	// Always cache the lenient parse, so the cache serves both modes.
+	records, headers, skipped, err := readCSV(filePath, false)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cached = quizCache{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Hash:    hash,
+		Headers: headers,
+		Records: records,
+		Skipped: skipped,
+	}
+	saveQuizCache(path, cached)
+	return cachedResult(cached, strict)
+}
+
+// cachedResult returns the contents of a cached quiz, failing on its first
+// malformed row in strict mode.
+func cachedResult(cached quizCache, strict bool) ([][]string, []string, []rowError, error) {
+	if strict && len(cached.Skipped) > 0 {
+		return nil, nil, nil, fmt.Errorf("error reading records: %w", cached.Skipped[0])
+	}
+	return cached.Records, cached.Headers, cached.Skipped, nil
+}
+
+// hashFile returns the SHA-256 hash of a file's content.
+func hashFile(filePath string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return sum, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return sum, fmt.Errorf("error reading file: %w", err)
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// loadQuizCache decodes a cached quiz.
+func loadQuizCache(path string) (quizCache, error) {
+	var cached quizCache
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cached, err
+	}
+
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&cached)
+	return cached, err
+}
+
+// saveQuizCache encodes a quiz into the cache, replacing any previous copy atomically.
+func saveQuizCache(path string, cached quizCache) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cached); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// A temporary file of its own, as the same quiz may be cached by several
+	// loads at once.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
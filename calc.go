@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Commands that can be typed in place of an answer.
+const (
+	commandCalc   = "calc"
+	commandNote   = "note"
+	commandHint   = "hint"
+	commandReport = "report"
+	commandQuit   = "quit"
+)
+
+// quizCommands are the commands allowed unless -commands says otherwise.
+var quizCommands = []string{commandCalc, commandNote, commandHint, commandReport, commandQuit}
+
+// parseCommand splits a line typed in place of an answer into a command and
+// its argument, e.g. "/calc 17*23" into "calc" and "17*23".
+// It reports false if the line is not a command.
+func parseCommand(line string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), "/")
+	if !ok {
+		return "", "", false
+	}
+	name, arg, _ := strings.Cut(rest, " ")
+	name = strings.ToLower(name)
+	for _, command := range quizCommands {
+		if name == command {
+			return name, strings.TrimSpace(arg), true
+		}
+	}
+	return "", "", false
+}
+
+// calculate evaluates an arithmetic expression with +, -, *, /, % (remainder),
+// ^ (power) and parentheses, following the usual precedence.
+//
+// Returns:
+//   - float64: the value of the expression.
+//   - error: an error if the expression is malformed or divides by zero.
+func calculate(expr string) (float64, error) {
+	p := &calcParser{input: expr}
+	value, err := p.sum()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected %q", p.input[p.pos:])
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("result is not a number")
+	}
+	return value, nil
+}
+
+// formatCalc renders a calculated value without needless digits, e.g. 391 or 0.5.
+func formatCalc(value float64) string {
+	return strconv.FormatFloat(value, 'g', 12, 64)
+}
+
+// calcParser is a recursive descent parser that evaluates as it parses.
+type calcParser struct {
+	input string
+	pos   int
+}
+
+func (p *calcParser) skipSpace() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// next returns the next non-space byte without consuming it, or 0 at the end.
+func (p *calcParser) next() byte {
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+// sum parses terms joined by + and -.
+func (p *calcParser) sum() (float64, error) {
+	value, err := p.product()
+	for err == nil {
+		op := p.next()
+		if op != '+' && op != '-' {
+			break
+		}
+		p.pos++
+		var rhs float64
+		if rhs, err = p.product(); op == '+' {
+			value += rhs
+		} else {
+			value -= rhs
+		}
+	}
+	return value, err
+}
+
+// product parses factors joined by *, / and %.
+func (p *calcParser) product() (float64, error) {
+	value, err := p.power()
+	for err == nil {
+		op := p.next()
+		if op != '*' && op != '/' && op != '%' {
+			break
+		}
+		p.pos++
+		var rhs float64
+		if rhs, err = p.power(); err != nil {
+			break
+		}
+		if op != '*' && rhs == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		switch op {
+		case '*':
+			value *= rhs
+		case '/':
+			value /= rhs
+		case '%':
+			value = math.Mod(value, rhs)
+		}
+	}
+	return value, err
+}
+
+// power parses a unary expression optionally raised to a power, which is right-associative.
+func (p *calcParser) power() (float64, error) {
+	base, err := p.unary()
+	if err != nil || p.next() != '^' {
+		return base, err
+	}
+	p.pos++
+	exponent, err := p.power()
+	return math.Pow(base, exponent), err
+}
+
+// unary parses a number or parenthesised expression, optionally negated.
+func (p *calcParser) unary() (float64, error) {
+	switch c := p.next(); {
+	case c == '-' || c == '+':
+		p.pos++
+		value, err := p.unary()
+		if c == '-' {
+			value = -value
+		}
+		return value, err
+	case c == '(':
+		p.pos++
+		value, err := p.sum()
+		if err != nil {
+			return 0, err
+		}
+		if p.next() != ')' {
+			return 0, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return value, nil
+	case c == '.' || unicode.IsDigit(rune(c)):
+		start := p.pos
+		for p.pos < len(p.input) && (p.input[p.pos] == '.' || unicode.IsDigit(rune(p.input[p.pos]))) {
+			p.pos++
+		}
+		return strconv.ParseFloat(p.input[start:p.pos], 64)
+	case c == 0:
+		return 0, fmt.Errorf("incomplete expression")
+	default:
+		return 0, fmt.Errorf("unexpected %q", p.input[p.pos:])
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+)
+
+// questionStats is the empirical difficulty of a question, measured from recorded attempts.
+type questionStats struct {
+	Attempts int
+	Correct  int
+	Seconds  float64
+}
+
+// difficulty returns the share of attempts answered wrongly, from 0 (always right) to 1 (always wrong).
+func (s questionStats) difficulty() float64 {
+	return 1 - float64(s.Correct)/float64(s.Attempts)
+}
+
+// averageSeconds returns the mean time taken to answer.
+func (s questionStats) averageSeconds() float64 {
+	return s.Seconds / float64(s.Attempts)
+}
+
+// collectQuestionStats tallies the outcomes of every question across attempts, keyed by question text.
+func collectQuestionStats(entries []historyEntry) map[string]questionStats {
+	stats := make(map[string]questionStats)
+	for _, entry := range entries {
+		for _, outcome := range entry.Questions {
+			if outcome.Reversed {
+				// Difficulty is measured for questions asked as written.
+				continue
+			}
+			s := stats[outcome.Question]
+			s.Attempts++
+			if outcome.Correct {
+				s.Correct++
+			}
+			s.Seconds += outcome.Seconds
+			stats[outcome.Question] = s
+		}
+	}
+	return stats
+}
+
+// runCalibrate implements the "calibrate" subcommand.
+//
+// It measures how difficult each question of a quiz really is from the
+// recorded attempts, by everyone who took it on this machine, and lists the
+// questions from hardest to easiest. With -o it writes the bank back out with
+// the measurements in its difficulty and avg_seconds columns.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the quiz or history cannot be read.
+func runCalibrate(ctx context.Context, args []string) error {
+	flags := newFlagSet("calibrate")
+	minAttempts := flags.Int("min-attempts", 3, "leave questions with fewer than `n` attempts uncalibrated")
+	output := flags.String("o", "", "write the calibrated bank as CSV to `file` (may be the quiz itself)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *minAttempts < 1 {
+		return usageErrorf("usage: go-quiz calibrate [-min-attempts n] [-o file] quiz.csv")
+	}
+
+	quiz, err := filepath.Abs(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error expanding path: %w", err)
+	}
+
+	entries, err := loadHistory(ctx, quiz)
+	if err != nil {
+		return err
+	}
+	stats := collectQuestionStats(entries)
+
+	records, headers, _, err := readQuizRecords(quiz)
+	if err != nil {
+		return err
+	}
+	questions, err := parseQuestions(records, headers, "")
+	if err != nil {
+		return err
+	}
+
+	writeCalibration(questions, stats, *minAttempts)
+
+	if *output == "" {
+		return nil
+	}
+	settings, err := readQuizSettings(quiz)
+	if err != nil {
+		return err
+	}
+	return writeCalibratedBank(*output, settings, records, headers, questions, stats, *minAttempts)
+}
+
+// writeCalibration prints the measured difficulty of every question, hardest first.
+func writeCalibration(questions []question, stats map[string]questionStats, minAttempts int) {
+	sorted := slices.Clone(questions)
+	slices.SortStableFunc(sorted, func(a, b question) int {
+		sa, sb := stats[a.Text], stats[b.Text]
+		calibratedA, calibratedB := sa.Attempts >= minAttempts, sb.Attempts >= minAttempts
+		if calibratedA != calibratedB {
+			if calibratedA {
+				return -1
+			}
+			return 1
+		}
+		if !calibratedA {
+			return 0
+		}
+		return cmp.Compare(sb.difficulty(), sa.difficulty())
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "ATTEMPTS\tCORRECT\tAVG TIME\t\tQUESTION")
+	for _, q := range sorted {
+		s := stats[q.Text]
+		text := abbreviate(q.Text, terminalWidth()-34)
+		if s.Attempts < minAttempts {
+			fmt.Fprintf(w, "%d\t-\t-\t\t%s\n", s.Attempts, text)
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%.0f%%\t%.1fs\t\t%s\n", s.Attempts, 100*(1-s.difficulty()), s.averageSeconds(), text)
+	}
+	w.Flush()
+}
+
+// writeCalibratedBank writes the bank as CSV to path with each calibrated
+// question's difficulty (the share of wrong answers, 0 to 1) and average time
+// in the difficulty and avg_seconds columns, which are added if missing.
+// Questions with too few attempts keep their existing values, and the quiz's
+// settings and other leading comments are kept. The file is written to a
+// temporary file first and renamed into place.
+func writeCalibratedBank(path string, settings quizSettings, records [][]string, headers []string, questions []question,
+	stats map[string]questionStats, minAttempts int) error {
+	headers = slices.Clone(headers)
+	difficultyCol := columnIndex(headers, "difficulty")
+	if difficultyCol < 0 {
+		headers = append(headers, "difficulty")
+		difficultyCol = len(headers) - 1
+	}
+	secondsCol := columnIndex(headers, "avg_seconds")
+	if secondsCol < 0 {
+		headers = append(headers, "avg_seconds")
+		secondsCol = len(headers) - 1
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".go-quiz-*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating output: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := settings.writePreamble(tmp); err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+	writer := csv.NewWriter(tmp)
+	writer.Write(headers)
+	for i, record := range records {
+		row := slices.Clone(record)
+		for len(row) < len(headers) {
+			row = append(row, "")
+		}
+		if s := stats[questions[i].Text]; s.Attempts >= minAttempts {
+			row[difficultyCol] = strconv.FormatFloat(s.difficulty(), 'f', 2, 64)
+			row[secondsCol] = strconv.FormatFloat(s.averageSeconds(), 'f', 1, 64)
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+
+	if err := tmp.Chmod(0o644); err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error saving output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Layout of flash cards on an A4 page: cardColumns by cardRows cards filling
+// the space inside the margins.
+const (
+	cardColumns  = 2
+	cardRows     = 4
+	cardPadding  = 12
+	cardTextSize = 14
+)
+
+// Edges of the sheet turned over when printing both sides, chosen with -flip.
+const (
+	flipLongEdge  = "long"
+	flipShortEdge = "short"
+)
+
+// writeCardsPDF writes flash cards for the questions as an A4 PDF document:
+// a page of question sides followed by a page of the matching answer sides,
+// laid out so that each answer lands on the back of its question when the
+// pages are printed on both sides of the sheet, turned over on flip.
+//
+// Parameters:
+//   - w: where to write the document.
+//   - questions: the questions, one per card.
+//   - flip: flipLongEdge or flipShortEdge.
+//
+// Returns:
+//   - error: an error if the document cannot be written.
+func writeCardsPDF(w io.Writer, questions []question, flip string) error {
+	width := float64(pdfPageWidth-2*pdfMargin) / cardColumns
+	height := float64(pdfPageHeight-2*pdfMargin) / cardRows
+	perPage := cardColumns * cardRows
+
+	var doc pdfDocument
+	for first := 0; first < len(questions); first += perPage {
+		sheet := questions[first:min(first+perPage, len(questions))]
+		for _, back := range []bool{false, true} {
+			doc.newPage()
+			for i, q := range sheet {
+				column, row := i%cardColumns, i/cardColumns
+				// The back of a sheet is mirrored: across for a long-edge flip,
+				// top to bottom for a short-edge one.
+				if back && flip == flipLongEdge {
+					column = cardColumns - 1 - column
+				} else if back {
+					row = cardRows - 1 - row
+				}
+				x := pdfMargin + float64(column)*width
+				y := pdfPageHeight - pdfMargin - float64(row+1)*height
+				doc.cutBox(x, y, width, height)
+
+				text := q.Text + "?"
+				if len(q.Choices) > 0 {
+					var choices []string
+					for j, choice := range q.Choices {
+						choices = append(choices, fmt.Sprintf("%c) %s", 'a'+j, choice))
+					}
+					text += "\n" + strings.Join(choices, "\n")
+				}
+				if back {
+					text = answerKey(q)
+				}
+				doc.centredText(text, cardTextSize, back, x+cardPadding, y+cardPadding, width-2*cardPadding, height-2*cardPadding)
+			}
+		}
+	}
+	return doc.writeTo(w)
+}
+
+// runCards implements the "cards" subcommand.
+//
+// It lays out a quiz as flash cards to print and cut out, for studying
+// offline: questions on the front and answers on the back, on alternate
+// pages lined up for printing on both sides of the paper.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, the quiz cannot be loaded,
+//     or the cards cannot be written.
+func runCards(ctx context.Context, args []string) error {
+	flags := newFlagSet("cards")
+	output := flags.String("o", "", "write the cards to `file`, a .pdf file")
+	flip := flags.String("flip", flipLongEdge, "`edge` the printer turns the paper over on for two-sided printing: long or short")
+	lang := flags.String("lang", "", "use the question_<lang> and answer_<lang> columns")
+	shuffle := flags.Bool("shuffle", false, "put the questions in random order")
+	limit := flags.Int("limit", 0, "include at most `n` questions (0 includes all)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *output == "" {
+		return usageErrorf("usage: go-quiz cards -o cards.pdf [-flip long|short] [-lang language] [-shuffle] [-limit n] quiz.csv")
+	}
+	if ext := strings.ToLower(filepath.Ext(*output)); ext != ".pdf" {
+		return fmt.Errorf("unsupported cards format %q: use .pdf", ext)
+	}
+	if *flip != flipLongEdge && *flip != flipShortEdge {
+		return fmt.Errorf("invalid -flip %q: use long or short", *flip)
+	}
+	filePath := flags.Arg(0)
+
+	settings, err := readQuizSettings(filePath)
+	if err != nil {
+		return err
+	}
+	questions, _, err := loadQuiz(filePath, loadOptions{Lang: *lang}, slog.Warn)
+	if err != nil {
+		return err
+	}
+	questions = selectQuestions(questions, *shuffle, settings.Constraints, *limit)
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("error creating output: %w", err)
+	}
+	defer file.Close()
+	if err := writeCardsPDF(file, questions, *flip); err != nil {
+		return err
+	}
+	notice(fmt.Sprintf("Wrote %d flash cards to %s", len(questions), *output), "file", *output)
+	return nil
+}
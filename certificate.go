@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Files in the state directory holding the key that signs certificates and
+// the certificates issued.
+const (
+	certificateKeyFileName = "certificate.key"
+	certificatesFileName   = "certificates.jsonl"
+)
+
+// certificateCodeLength is the number of characters of a verification code,
+// without dashes: 80 bits of the record's signature.
+const certificateCodeLength = 16
+
+// certificate is the record of a passed quiz or course, kept in the state
+// directory so its verification code can be checked.
+type certificate struct {
+	// Code is the verification code, the start of an HMAC-SHA256 of the other fields.
+	Code  string    `json:"code"`
+	User  string    `json:"user"`
+	Title string    `json:"title"`
+	Score float64   `json:"score"`
+	Pass  float64   `json:"pass"`
+	Time  time.Time `json:"time"`
+}
+
+// validateCertificatePath reports an error if a certificate cannot be written
+// in the format of the file's extension.
+func validateCertificatePath(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt", ".pdf":
+		return nil
+	}
+	return fmt.Errorf("unsupported certificate format %q: use .txt or .pdf", filepath.Ext(path))
+}
+
+// certificateKey returns the key certificates are signed with, creating it
+// in the state directory on first use.
+func certificateKey() ([]byte, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, certificateKeyFileName)
+	key, err := os.ReadFile(path)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error reading certificate key: %w", err)
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("error generating certificate key: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating state directory: %w", err)
+	}
+	if err := os.WriteFile(path, key, 0o600); err != nil {
+		return nil, fmt.Errorf("error writing certificate key: %w", err)
+	}
+	return key, nil
+}
+
+// sign returns the verification code of a certificate's fields.
+func (c certificate) sign(key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s\n%s\n%g\n%g\n%s", c.User, c.Title, c.Score, c.Pass, c.Time.UTC().Format(time.RFC3339))
+	return normalizeCode(base32.StdEncoding.EncodeToString(mac.Sum(nil))[:certificateCodeLength])
+}
+
+// normalizeCode puts a verification code as typed into its printed form,
+// e.g. "abcdefgh..." into "ABCD-EFGH-...".
+func normalizeCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	var groups []string
+	for i := 0; i < len(code); i += 4 {
+		groups = append(groups, code[i:min(i+4, len(code))])
+	}
+	return strings.Join(groups, "-")
+}
+
+// issueCertificate signs a certificate for a passed quiz or course, records it
+// and writes it to a text or PDF file.
+//
+// Parameters:
+//   - path: the file to write, a .txt or .pdf file.
+//   - title: the name of the quiz or course passed.
+//   - score, pass: the score reached and the pass mark, in percent.
+//
+// Returns:
+//   - error: an error if the certificate cannot be signed, recorded or written.
+func issueCertificate(path, title string, score, pass float64) error {
+	key, err := certificateKey()
+	if err != nil {
+		return err
+	}
+	c := certificate{User: currentUser(), Title: title, Score: score, Pass: pass, Time: time.Now().UTC().Truncate(time.Second)}
+	c.Code = c.sign(key)
+
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, certificatesFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening certificates: %w", err)
+	}
+	defer file.Close()
+	line, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("error encoding certificate: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error recording certificate: %w", err)
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating certificate: %w", err)
+	}
+	defer out.Close()
+	if strings.EqualFold(filepath.Ext(path), ".pdf") {
+		err = c.writePDF(out)
+	} else {
+		err = c.writeText(out)
+	}
+	if err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("error writing certificate: %w", err)
+	}
+	fmt.Fprintf(stdout, "Certificate written to %s (verification code %s).\n", path, c.Code)
+	return nil
+}
+
+// passMark describes the pass mark, if there was one, e.g. " (pass mark 80%)".
+func (c certificate) passMark() string {
+	if c.Pass == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (pass mark %g%%)", c.Pass)
+}
+
+// lines returns the text of the certificate, line by line, with blank lines between parts.
+func (c certificate) lines() []string {
+	return []string{
+		"This certifies that",
+		"    " + c.User,
+		"passed",
+		"    " + c.Title,
+		fmt.Sprintf("with a score of %.0f%%%s on %s.", c.Score, c.passMark(), c.Time.Local().Format("2 January 2006")),
+		"",
+		"Verification code: " + c.Code,
+		"Check it with: go-quiz verify-cert " + c.Code,
+	}
+}
+
+// writeText writes the certificate as plain text.
+func (c certificate) writeText(w io.Writer) error {
+	text := "Certificate of completion\n\n" + strings.Join(c.lines(), "\n") + "\n"
+	if _, err := io.WriteString(w, text); err != nil {
+		return fmt.Errorf("error writing certificate: %w", err)
+	}
+	return nil
+}
+
+// writePDF writes the certificate as an A4 PDF document.
+func (c certificate) writePDF(w io.Writer) error {
+	var doc pdfDocument
+	doc.gap(120)
+	doc.text("Certificate of completion", 28, true, 0, 0)
+	doc.gap(40)
+	for _, line := range c.lines() {
+		if strings.HasPrefix(line, "    ") {
+			doc.text(strings.TrimSpace(line), 20, true, 2, 0)
+		} else {
+			doc.text(line, 14, false, 0, 0)
+		}
+		doc.gap(10)
+	}
+	return doc.writeTo(w)
+}
+
+// loadCertificates reads the certificates issued from the state directory.
+func loadCertificates() ([]certificate, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filepath.Join(dir, certificatesFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening certificates: %w", err)
+	}
+	defer file.Close()
+
+	var certificates []certificate
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var c certificate
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("error parsing certificates: %w", err)
+		}
+		certificates = append(certificates, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading certificates: %w", err)
+	}
+	return certificates, nil
+}
+
+// runVerifyCert implements the "verify-cert" subcommand.
+//
+// It checks a certificate's verification code against the certificates issued
+// on this machine, and that the record has not been altered since, by signing
+// it again with the certificate key.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, the certificates cannot be
+//     read, or the code is not that of a genuine certificate.
+func runVerifyCert(ctx context.Context, args []string) error {
+	flags := newFlagSet("verify-cert")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageErrorf("usage: go-quiz verify-cert CODE")
+	}
+	code := normalizeCode(flags.Arg(0))
+
+	certificates, err := loadCertificates()
+	if err != nil {
+		return err
+	}
+	if len(certificates) == 0 {
+		return fmt.Errorf("no certificates have been issued here")
+	}
+	key, err := certificateKey()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range certificates {
+		if c.Code != code {
+			continue
+		}
+		if !hmac.Equal([]byte(c.sign(key)), []byte(c.Code)) {
+			return fmt.Errorf("certificate %s has been altered", code)
+		}
+		fmt.Fprintf(stdout, "Certificate %s is genuine: %s passed %s with %.0f%%%s on %s.\n",
+			code, c.User, c.Title, c.Score, c.passMark(), c.Time.Local().Format("2 January 2006"))
+		return nil
+	}
+	return fmt.Errorf("no certificate with code %s was issued here", code)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{name: "printed form", code: "ABCD-EFGH-IJKL", want: "ABCD-EFGH-IJKL"},
+		{name: "lower case", code: "abcdefghijkl", want: "ABCD-EFGH-IJKL"},
+		{name: "spaces and dashes", code: " ab cd-efgh ij-kl ", want: "ABCD-EFGH-IJKL"},
+		{name: "short last group", code: "abcdef", want: "ABCD-EF"},
+		{name: "empty", code: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeCode(tt.code); got != tt.want {
+				t.Errorf("normalizeCode(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertificateSign(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	issued := certificate{User: "alice", Title: "Capitals", Score: 90, Pass: 80, Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	code := issued.sign(key)
+	if normalizeCode(code) != code {
+		t.Errorf("sign = %q, not in its printed form", code)
+	}
+	if got := issued.sign(key); got != code {
+		t.Errorf("sign = %q, then %q: want the same code", code, got)
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *certificate)
+		key    []byte
+	}{
+		{name: "user", change: func(c *certificate) { c.User = "bob" }},
+		{name: "title", change: func(c *certificate) { c.Title = "Rivers" }},
+		{name: "score", change: func(c *certificate) { c.Score = 91 }},
+		{name: "pass mark", change: func(c *certificate) { c.Pass = 70 }},
+		{name: "time", change: func(c *certificate) { c.Time = c.Time.Add(time.Second) }},
+		{name: "key", key: []byte("another key")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, k := issued, key
+			if tt.change != nil {
+				tt.change(&c)
+			}
+			if tt.key != nil {
+				k = tt.key
+			}
+			if got := c.sign(k); got == code {
+				t.Errorf("sign = %q with a different %s, want another code", got, tt.name)
+			}
+		})
+	}
+
+	// The time is signed in UTC, whatever zone it is given in.
+	local := issued
+	local.Time = issued.Time.In(time.FixedZone("CEST", 2*60*60))
+	if got := local.sign(key); got != code {
+		t.Errorf("sign = %q in another zone, want %q", got, code)
+	}
+}
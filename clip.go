@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands lists, in order of preference, the commands that print
+// the clipboard's text on the current platform.
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbpaste"}}
+	case "windows":
+		return [][]string{{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"}}
+	default:
+		commands := [][]string{
+			{"xclip", "-selection", "clipboard", "-o"},
+			{"xsel", "--clipboard", "--output"},
+		}
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			commands = append([][]string{{"wl-paste", "--no-newline"}}, commands...)
+		}
+		return commands
+	}
+}
+
+// readClipboard returns the text on the system clipboard, using the first
+// available clipboard command.
+func readClipboard() (string, error) {
+	var tried []string
+	for _, command := range clipboardCommands() {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			tried = append(tried, command[0])
+			continue
+		}
+
+		var stderr bytes.Buffer
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("error reading clipboard with %s: %w: %s", command[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return string(out), nil
+	}
+	return "", fmt.Errorf("no clipboard command found; install one of: %s", strings.Join(tried, ", "))
+}
+
+// parseClipboard reads quiz rows from pasted text.
+//
+// The text may be CSV, tab-separated values as copied from a spreadsheet, or
+// a Markdown table, which may be surrounded by other text. Text containing a
+// table delimiter row such as |---|---| is read as Markdown; otherwise it is
+// tab-separated if its first line contains a tab, and CSV if not.
+//
+// Parameters:
+//   - text: the pasted text.
+//   - strict: whether a malformed row is an error instead of being skipped.
+//
+// Returns:
+//   - [][]string: the rows after the first one.
+//   - []string: the first row.
+//   - []rowError: the malformed rows that were skipped.
+//   - error: an error if the text has no rows or, in strict mode, a malformed row.
+func parseClipboard(text string, strict bool) ([][]string, []string, []rowError, error) {
+	text = strings.TrimLeft(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	firstLine, _, _ := strings.Cut(text, "\n")
+	if strings.TrimSpace(firstLine) == "" {
+		return nil, nil, nil, errors.New("the clipboard is empty")
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "|") && isMarkdownDelimiter(strings.Split(strings.Trim(line, "|"), "|")) {
+			return parseMarkdownTable(text, strict)
+		}
+	}
+
+	src := strings.NewReader(text)
+	reader := csv.NewReader(src)
+	if strings.Contains(firstLine, "\t") {
+		reader.Comma = '\t'
+		// Spreadsheets only quote cells containing tabs or newlines, so other quotes are literal.
+		reader.LazyQuotes = true
+	}
+
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading headers: %w", err)
+	}
+
+	var records [][]string
+	skipped, err := readRows(reader, src, strict, func(_ int64, record []string) {
+		records = append(records, record)
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading records: %w", err)
+	}
+	return records, headers, skipped, nil
+}
+
+// parseMarkdownTable reads the rows of a Markdown table, skipping its
+// delimiter row and any lines that are not part of the table.
+func parseMarkdownTable(text string, strict bool) ([][]string, []string, []rowError, error) {
+	var headers []string
+	var records [][]string
+	var skipped []rowError
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			continue
+		}
+
+		cells := strings.Split(strings.Trim(line, "|"), "|")
+		for j := range cells {
+			cells[j] = strings.TrimSpace(cells[j])
+		}
+		if isMarkdownDelimiter(cells) {
+			continue
+		}
+
+		if headers == nil {
+			headers = cells
+			continue
+		}
+		if len(cells) != len(headers) {
+			rowErr := rowError{
+				Line:    i + 1,
+				Reason:  fmt.Sprintf("wrong number of fields: expected %d, found %d (is there a | inside a cell?)", len(headers), len(cells)),
+				Content: abbreviate(line, maxRowContent),
+			}
+			if strict {
+				return nil, nil, nil, fmt.Errorf("error reading records: %w", rowErr)
+			}
+			skipped = append(skipped, rowErr)
+			continue
+		}
+		records = append(records, cells)
+	}
+
+	return records, headers, skipped, nil
+}
+
+// isMarkdownDelimiter reports whether cells form the delimiter row under a
+// Markdown table's header, such as |---|:---:|.
+func isMarkdownDelimiter(cells []string) bool {
+	for _, cell := range cells {
+		cell = strings.TrimSpace(cell)
+		if cell == "" || strings.Trim(cell, "-:") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// runClip implements the "clip" subcommand.
+//
+// It runs a quiz on CSV, tab-separated or Markdown table data copied to the
+// system clipboard. Nothing is written to disk: the attempt is not recorded
+// in the history and no cache or index is built, although -results still
+// writes the file it is given.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the clipboard cannot be read.
+func runClip(ctx context.Context, args []string) error {
+	flags := newFlagSet("clip")
+	opts := registerQuizFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return usageErrorf("usage: go-quiz clip [quiz flags]")
+	}
+	if opts.load.Indexed || opts.load.Sheet != "" || opts.load.Columns != "" {
+		return fmt.Errorf("-indexed, -sheet and -columns cannot be used with clip")
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	text, err := readClipboard()
+	if err != nil {
+		return err
+	}
+
+	records, headers, skipped, err := parseClipboard(text, opts.load.StrictParse)
+	if err != nil {
+		return err
+	}
+
+	questions, headers, err := buildQuiz(records, headers, skipped, opts.load, slog.Warn)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Number of records: %d\n", len(questions))
+
+	questions = applyDirection(questions, opts.direction)
+	questions = selectQuestions(questions, opts.load.Shuffle, shuffleConstraints{}, opts.load.Limit)
+
+	return runSession(ctx, opts, flags, session{
+		questions:  questions,
+		byCategory: columnIndex(headers, "category") >= 0,
+	})
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+func init() {
+	// Registered here rather than in the subcommands literal, which
+	// runCompletion reads, to avoid an initialization cycle.
+	subcommands["completion"] = runCompletion
+}
+
+// completionShells maps each supported shell to the function writing its completion script.
+var completionShells = map[string]func(w io.Writer, commands []string, flags map[string][]completionFlag){
+	"bash":       writeBashCompletion,
+	"zsh":        writeZshCompletion,
+	"fish":       writeFishCompletion,
+	"powershell": writePowerShellCompletion,
+}
+
+// completionActions lists the subcommands whose first argument names an
+// action with flags of its own, such as "fetch opentdb".
+var completionActions = map[string]map[string]func(ctx context.Context, args []string) error{
+	"fetch": fetchSources,
+	"user":  userActions,
+}
+
+// completionExtensions lists the extensions of the quiz files offered for
+// completion.
+var completionExtensions = []string{"csv", "xlsx", "txt"}
+
+// completionProbeFlag is the undefined flag runCompletion passes to a
+// subcommand to make it stop as soon as its flags are defined.
+const completionProbeFlag = "-go-quiz-completion-probe"
+
+// completionFlag is a flag offered for completion.
+type completionFlag struct {
+	// Name is the flag with its leading dash, e.g. "-shuffle".
+	Name string
+	// Usage is the flag's description, as shown by -help.
+	Usage string
+}
+
+// runCompletion implements the "completion" subcommand.
+//
+// It prints a completion script for the given shell covering the
+// subcommands, the flags of each, and the quiz files in the current
+// directory. The flags of the quiz itself are offered when there is no
+// subcommand.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the shell is missing or unsupported.
+func runCompletion(ctx context.Context, args []string) error {
+	if len(args) != 1 || completionShells[args[0]] == nil {
+		return usageErrorf("usage: go-quiz completion bash|zsh|fish|powershell")
+	}
+
+	commands := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		commands = append(commands, name)
+	}
+	slices.Sort(commands)
+
+	quizFlags := flag.NewFlagSet("go-quiz", flag.ContinueOnError)
+	registerQuizFlags(quizFlags)
+	registerLogFlags(quizFlags)
+	flags := map[string][]completionFlag{"": completionFlags([]*flag.FlagSet{quizFlags})}
+	for _, name := range commands {
+		var sets []*flag.FlagSet
+		switch actions := completionActions[name]; {
+		case name == "completion":
+		case actions != nil:
+			for _, action := range slices.Sorted(maps.Keys(actions)) {
+				sets = append(sets, probeFlagSets(ctx, subcommands[name], action)...)
+			}
+		default:
+			sets = probeFlagSets(ctx, subcommands[name])
+		}
+		flags[name] = completionFlags(sets)
+	}
+
+	completionShells[args[0]](os.Stdout, commands, flags)
+	return nil
+}
+
+// probeFlagSets returns the flag sets a subcommand defines, by running it
+// with completionProbeFlag after args, which its flag set fails to parse
+// before the subcommand does anything.
+func probeFlagSets(ctx context.Context, run func(ctx context.Context, args []string) error, args ...string) (sets []*flag.FlagSet) {
+	probedFlagSets = &sets
+	defer func() {
+		probedFlagSets = nil
+		if r := recover(); r != nil {
+			if _, ok := r.(error); !ok {
+				panic(r)
+			}
+		}
+	}()
+	run(ctx, append(args, completionProbeFlag))
+	return sets
+}
+
+// completionFlags lists the flags defined in sets, each name once, sorted by
+// name.
+func completionFlags(sets []*flag.FlagSet) []completionFlag {
+	var flags []completionFlag
+	seen := make(map[string]bool)
+	for _, set := range sets {
+		set.VisitAll(func(f *flag.Flag) {
+			if seen[f.Name] {
+				return
+			}
+			seen[f.Name] = true
+			_, usage := flag.UnquoteUsage(f)
+			flags = append(flags, completionFlag{Name: "-" + f.Name, Usage: usage})
+		})
+	}
+	slices.SortFunc(flags, func(a, b completionFlag) int { return strings.Compare(a.Name, b.Name) })
+	return flags
+}
+
+// flagNames returns the names of flags, separated by spaces.
+func flagNames(flags []completionFlag) string {
+	names := make([]string, 0, len(flags))
+	for _, f := range flags {
+		names = append(names, f.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+// writeBashCompletion writes a completion script for bash.
+func writeBashCompletion(w io.Writer, commands []string, flags map[string][]completionFlag) {
+	var cases strings.Builder
+	for _, name := range commands {
+		if len(flags[name]) > 0 {
+			fmt.Fprintf(&cases, "        %s) flags=%q ;;\n", name, flagNames(flags[name]))
+		}
+	}
+	fmt.Fprintf(w, `# bash completion for go-quiz
+# Load with: source <(go-quiz completion bash)
+_go_quiz() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    if [[ "$cur" == -* ]]; then
+        local flags=""
+        case "${COMP_WORDS[1]}" in
+%s        %s) ;;
+        *) flags=%q ;;
+        esac
+        COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+        return
+    fi
+    COMPREPLY=()
+    local ext
+    for ext in %s; do
+        COMPREPLY+=($(compgen -f -X "!*.$ext" -- "$cur"))
+    done
+    if [[ $COMP_CWORD -eq 1 ]]; then
+        COMPREPLY+=($(compgen -W "%s" -- "$cur"))
+    fi
+}
+complete -o filenames -F _go_quiz go-quiz
+`, cases.String(), strings.Join(commands, "|"), flagNames(flags[""]),
+		strings.Join(completionExtensions, " "), strings.Join(commands, " "))
+}
+
+// writeZshCompletion writes a completion script for zsh.
+func writeZshCompletion(w io.Writer, commands []string, flags map[string][]completionFlag) {
+	var cases strings.Builder
+	for _, name := range commands {
+		if len(flags[name]) > 0 {
+			fmt.Fprintf(&cases, "        %s) compadd -- %s ;;\n", name, flagNames(flags[name]))
+		}
+	}
+	fmt.Fprintf(w, `#compdef go-quiz
+# zsh completion for go-quiz
+# Load with: source <(go-quiz completion zsh)
+_go_quiz() {
+    if [[ $words[CURRENT] == -* ]]; then
+        case $words[2] in
+%s        %s) ;;
+        *) compadd -- %s ;;
+        esac
+        return
+    fi
+    if (( CURRENT == 2 )); then
+        compadd -- %s
+    fi
+    _files -g '*.(%s)'
+}
+compdef _go_quiz go-quiz
+`, cases.String(), strings.Join(commands, "|"), flagNames(flags[""]), strings.Join(commands, " "),
+		strings.Join(completionExtensions, "|"))
+}
+
+// writeFishCompletion writes a completion script for fish, including flag descriptions.
+func writeFishCompletion(w io.Writer, commands []string, flags map[string][]completionFlag) {
+	fmt.Fprintln(w, "# fish completion for go-quiz")
+	fmt.Fprintln(w, "# Load with: go-quiz completion fish | source")
+	fmt.Fprintln(w, "complete -c go-quiz -f")
+	fmt.Fprintf(w, "complete -c go-quiz -n __fish_use_subcommand -a '%s'\n", strings.Join(commands, " "))
+	quiz := fishQuote("not __fish_seen_subcommand_from " + strings.Join(commands, " "))
+	for _, f := range flags[""] {
+		fmt.Fprintf(w, "complete -c go-quiz -n %s -o %s -d %s\n", quiz, strings.TrimPrefix(f.Name, "-"), fishQuote(f.Usage))
+	}
+	for _, name := range commands {
+		for _, f := range flags[name] {
+			fmt.Fprintf(w, "complete -c go-quiz -n %s -o %s -d %s\n",
+				fishQuote("__fish_seen_subcommand_from "+name), strings.TrimPrefix(f.Name, "-"), fishQuote(f.Usage))
+		}
+	}
+	for _, ext := range completionExtensions {
+		fmt.Fprintf(w, "complete -c go-quiz -a '(__fish_complete_suffix .%s)'\n", ext)
+	}
+}
+
+// fishQuote quotes s as a single-quoted fish string.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// writePowerShellCompletion writes a completion script for PowerShell.
+func writePowerShellCompletion(w io.Writer, commands []string, flags map[string][]completionFlag) {
+	var table strings.Builder
+	for _, name := range append([]string{""}, commands...) {
+		names := make([]string, 0, len(flags[name]))
+		for _, f := range flags[name] {
+			names = append(names, f.Name)
+		}
+		fmt.Fprintf(&table, "    %s = @(%s)\n", powerShellList([]string{name}), powerShellList(names))
+	}
+	fmt.Fprintf(w, `# PowerShell completion for go-quiz
+# Load with: go-quiz completion powershell | Out-String | Invoke-Expression
+$goQuizFlags = @{
+%s}
+Register-ArgumentCompleter -Native -CommandName go-quiz -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $candidates = @()
+    if ($wordToComplete -like '-*') {
+        $command = ''
+        if ($commandAst.CommandElements.Count -gt 1) {
+            $command = "$($commandAst.CommandElements[1])"
+        }
+        if (-not $goQuizFlags.ContainsKey($command)) {
+            $command = ''
+        }
+        $candidates = $goQuizFlags[$command]
+    } else {
+        if ($commandAst.CommandElements.Count -le 2) {
+            $candidates += @(%s)
+        }
+        $candidates += Get-ChildItem -Name -File | Where-Object { $_ -match '\.(%s)$' }
+    }
+    $candidates | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`, table.String(), powerShellList(commands), strings.Join(completionExtensions, "|"))
+}
+
+// powerShellList renders words as a comma-separated list of PowerShell string literals.
+func powerShellList(words []string) string {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		quoted = append(quoted, "'"+strings.ReplaceAll(word, "'", "''")+"'")
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// courseStep is a quiz in a course manifest.
+type courseStep struct {
+	// Name is the quiz as written in the manifest, used to refer to it.
+	Name string
+	// Path is the absolute path of the quiz file.
+	Path string
+	// Requires names the quizzes that must be passed before this one is unlocked.
+	Requires []string
+	// Pass is the score needed to pass, in percent; 0 passes any attempt.
+	Pass float64
+}
+
+// readCourse reads a course manifest: a CSV file with a quiz column, the
+// path of each quiz relative to the manifest, and optional requires and pass
+// columns. requires lists the quizzes, separated by "|", that must be passed
+// first, and pass the score needed to pass the quiz, e.g.
+//
+//	quiz,requires,pass
+//	basics.csv,,80
+//	grammar.csv,basics.csv,80
+//
+// Returns:
+//   - []courseStep: the quizzes of the course, in manifest order.
+//   - error: an error if the manifest cannot be read, or is malformed; a quiz
+//     may only require quizzes listed before it, so a course has no cycles.
+func readCourse(filePath string) ([]courseStep, error) {
+	records, headers, skipped, err := readCSV(filePath, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		return nil, asParseError(filePath, fmt.Errorf("malformed course: %s", formatSkipped(skipped)))
+	}
+
+	quizCol := columnIndex(headers, "quiz")
+	requiresCol := columnIndex(headers, "requires")
+	passCol := columnIndex(headers, "pass")
+	if quizCol < 0 {
+		return nil, asParseError(filePath, fmt.Errorf("the course has no quiz column"))
+	}
+
+	var steps []courseStep
+	dir := filepath.Dir(filePath)
+	for _, row := range records {
+		step := courseStep{Name: strings.TrimSpace(row[quizCol])}
+		if step.Name == "" {
+			continue
+		}
+		step.Path = step.Name
+		if !filepath.IsAbs(step.Path) {
+			step.Path = filepath.Join(dir, step.Path)
+		}
+		if step.Path, err = filepath.Abs(step.Path); err != nil {
+			return nil, fmt.Errorf("error expanding path: %w", err)
+		}
+		if requiresCol >= 0 && strings.TrimSpace(row[requiresCol]) != "" {
+			for _, name := range strings.Split(row[requiresCol], choiceSeparator) {
+				name = strings.TrimSpace(name)
+				if !slices.ContainsFunc(steps, func(s courseStep) bool { return s.Name == name }) {
+					return nil, asParseError(filePath, fmt.Errorf("%s requires %q, which is not listed before it in the course", step.Name, name))
+				}
+				step.Requires = append(step.Requires, name)
+			}
+		}
+		if passCol >= 0 && strings.TrimSpace(row[passCol]) != "" {
+			step.Pass, err = strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(row[passCol]), "%"), 64)
+			if err != nil || step.Pass < 0 || step.Pass > 100 {
+				return nil, asParseError(filePath, fmt.Errorf("invalid pass mark %q for %s: use a percentage between 0 and 100", row[passCol], step.Name))
+			}
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		return nil, asParseError(filePath, fmt.Errorf("the course lists no quizzes"))
+	}
+	return steps, nil
+}
+
+// stepProgress is how far a user has got with a quiz of a course.
+type stepProgress struct {
+	Attempts int
+	// Best is the best score of the user's attempts, in percent.
+	Best     float64
+	Passed   bool
+	Unlocked bool
+}
+
+// courseProgress works out a user's progress through a course from the history.
+//
+// Parameters:
+//   - steps: the quizzes of the course, in manifest order.
+//   - entries: the recorded attempts on every quiz.
+//   - user: the user whose progress to work out.
+//
+// Returns:
+//   - []stepProgress: the progress on each quiz, in manifest order.
+func courseProgress(steps []courseStep, entries []historyEntry, user string) []stepProgress {
+	progress := make([]stepProgress, len(steps))
+	passed := make(map[string]bool)
+	for i, step := range steps {
+		p := &progress[i]
+		for _, e := range entries {
+			if e.Quiz == step.Path && e.User == user {
+				p.Attempts++
+				p.Best = max(p.Best, e.Score)
+			}
+		}
+		p.Passed = p.Attempts > 0 && p.Best >= step.Pass
+		p.Unlocked = true
+		for _, name := range step.Requires {
+			p.Unlocked = p.Unlocked && passed[name]
+		}
+		// A quiz passed before its prerequisites does not count until they are.
+		passed[step.Name] = p.Passed && p.Unlocked
+	}
+	return progress
+}
+
+// nextStep returns the index of the first unlocked quiz the user has not
+// passed, or -1 if they have finished the course.
+func nextStep(progress []stepProgress) int {
+	return slices.IndexFunc(progress, func(p stepProgress) bool { return p.Unlocked && !p.Passed })
+}
+
+// writeCourseProgress prints each quiz of a course with its status: passed,
+// open or locked, with the quizzes still to pass.
+func writeCourseProgress(w io.Writer, steps []courseStep, progress []stepProgress) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	passed := 0
+	for i, step := range steps {
+		p := progress[i]
+		status := "open"
+		switch {
+		case p.Passed && p.Unlocked:
+			status = "✓ passed"
+			passed++
+		case !p.Unlocked:
+			var missing []string
+			for _, name := range step.Requires {
+				j := slices.IndexFunc(steps, func(s courseStep) bool { return s.Name == name })
+				if !progress[j].Passed || !progress[j].Unlocked {
+					missing = append(missing, name)
+				}
+			}
+			status = "locked: pass " + strings.Join(missing, ", ") + " first"
+		}
+		best := "-"
+		if p.Attempts > 0 {
+			best = fmt.Sprintf("%.0f%%", p.Best)
+		}
+		fmt.Fprintf(tw, "  %s\tbest %s\tpass %g%%\t%s\n", step.Name, best, step.Pass, status)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "Passed %d of %d quizzes.\n", passed, len(steps))
+}
+
+// runCourse implements the "course" subcommand.
+//
+// It shows a user's progress through a course: quizzes chained by a manifest
+// (see readCourse), each unlocked once the quizzes it requires are passed.
+// Progress is worked out from each user's attempts in the history. With
+// -take, the next quiz of the course is taken, with the course's pass mark,
+// and -certificate writes a certificate once the course is complete, with
+// the average of the best scores.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, or the manifest or the
+//     history cannot be read; with -take, as for the quiz taken.
+func runCourse(ctx context.Context, args []string) error {
+	flags := newFlagSet("course")
+	opts := registerQuizFlags(flags)
+	user := flags.String("user", currentUser(), "show the progress of `name`")
+	take := flags.Bool("take", false, "take the next quiz of the course")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || (*take && *user != currentUser()) {
+		return usageErrorf("usage: go-quiz course [-user name | -take [quiz flags]] course.csv")
+	}
+
+	steps, err := readCourse(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	entries, err := loadHistory(ctx, "")
+	if err != nil {
+		return err
+	}
+	progress := courseProgress(steps, entries, *user)
+
+	fmt.Fprintf(stdout, "Course %s for %s:\n", displayPath(flags.Arg(0)), *user)
+	writeCourseProgress(stdout, steps, progress)
+
+	next := nextStep(progress)
+	switch {
+	case next < 0:
+		fmt.Fprintln(stdout, "Course complete!")
+		return nil
+	case !*take:
+		fmt.Fprintf(stdout, "Next: %s (go-quiz course -take %s)\n", steps[next].Name, flags.Arg(0))
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "\nTaking %s\n", steps[next].Name)
+	if settings, err := readQuizSettings(steps[next].Path); err != nil {
+		return err
+	} else if err := asParseError(steps[next].Path, settings.apply(flags)); err != nil {
+		return err
+	}
+	// The course's pass mark unlocks the next quiz, so it overrides the quiz's own.
+	opts.pass = steps[next].Pass
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if err := takeCourseQuiz(ctx, opts, steps[next].Path, flags); err != nil || opts.certificate == "" {
+		return err
+	}
+
+	// A certificate is for the whole course, so only the last quiz passed earns it.
+	if entries, err = loadHistory(ctx, ""); err != nil {
+		return err
+	}
+	progress = courseProgress(steps, entries, *user)
+	if nextStep(progress) >= 0 {
+		return nil
+	}
+	fmt.Fprintln(stdout, "Course complete!")
+	var total float64
+	for _, p := range progress {
+		total += p.Best
+	}
+	title := strings.TrimSuffix(filepath.Base(flags.Arg(0)), filepath.Ext(flags.Arg(0)))
+	return issueCertificate(opts.certificate, title, total/float64(len(progress)), 0)
+}
+
+// takeCourseQuiz takes a quiz of a course and records the attempt.
+func takeCourseQuiz(ctx context.Context, opts *quizOptions, filePath string, flags *flag.FlagSet) error {
+	questions, headers, err := loadQuiz(filePath, opts.load, slog.Warn)
+	if err != nil {
+		return err
+	}
+	questions = applyDirection(questions, opts.direction)
+	questions = selectQuestions(questions, opts.load.Shuffle, shuffleConstraints{}, opts.load.Limit)
+	if len(questions) == 0 {
+		return fmt.Errorf("no questions to ask")
+	}
+
+	// No title: the certificate is for the whole course (see runCourse).
+	return runSession(ctx, opts, flags, session{
+		quiz:       filePath,
+		questions:  questions,
+		byCategory: columnIndex(headers, "category") >= 0,
+		attempt:    &historyEntry{},
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// Cue kinds accepted by -cues besides the path of a sound file.
+const (
+	cueBell  = "bell"
+	cueFlash = "flash"
+)
+
+// flashDuration is how long the screen stays in reverse video for a flash cue.
+const flashDuration = 150 * time.Millisecond
+
+// finalCue is the time left at which a late cue is given, in addition to the
+// halfway cue, when the time limit is long enough for both to be useful.
+const finalCue = 10 * time.Second
+
+// soundCommands lists, in order of preference, the commands that play a sound
+// file on the current platform. The file's path is appended to the command,
+// except on Windows where it is substituted into the script.
+func soundCommands(path string) [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"afplay", path}}
+	case "windows":
+		script := fmt.Sprintf("(New-Object Media.SoundPlayer '%s').PlaySync()", path)
+		return [][]string{{"powershell", "-NoProfile", "-Command", script}}
+	default:
+		return [][]string{{"paplay", path}, {"pw-play", path}, {"aplay", "-q", path}}
+	}
+}
+
+// validateCue reports an error if cue is neither a known kind nor a sound
+// file that can be played.
+func validateCue(cue string) error {
+	if cue == "" || cue == cueBell || cue == cueFlash {
+		return nil
+	}
+	if _, err := os.Stat(cue); err != nil {
+		return fmt.Errorf("-cues must be %s, %s or a sound file: %w", cueBell, cueFlash, err)
+	}
+	for _, command := range soundCommands(cue) {
+		if _, err := exec.LookPath(command[0]); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("no command found to play %s", cue)
+}
+
+// cueMilestones returns the times left at which a question with the given
+// time limit gives a cue, latest first: halfway through, and finalCue before
+// the end if that is well after halfway.
+func cueMilestones(timeout time.Duration) []time.Duration {
+	milestones := []time.Duration{timeout / 2}
+	if timeout >= 3*finalCue {
+		milestones = append(milestones, finalCue)
+	}
+	return milestones
+}
+
+// playCue gives a cue: a terminal bell, a brief flash of the screen, or a
+// sound file played in the background.
+//
+// Parameters:
+//   - w: the terminal the bell and flash are written to.
+//   - cue: the kind of cue, as given with -cues; empty gives none.
+func playCue(w io.Writer, cue string) {
+	switch cue {
+	case "":
+	case cueBell:
+		fmt.Fprint(w, "\a")
+	case cueFlash:
+		// Turn on reverse video for the whole screen, then back off.
+		fmt.Fprint(w, "\x1b[?5h")
+		time.AfterFunc(flashDuration, func() {
+			fmt.Fprint(w, "\x1b[?5l")
+		})
+	default:
+		for _, command := range soundCommands(cue) {
+			if _, err := exec.LookPath(command[0]); err == nil {
+				// The sound plays while the quiz goes on; a failure only means no sound.
+				go exec.Command(command[0], command[1:]...).Run()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+	"time"
+)
+
+// defaultDailyCount is the number of questions in a daily quiz.
+const defaultDailyCount = 10
+
+// dailyQuestions picks the questions of the daily quiz of a date. The pick
+// and its order depend only on the date and the questions, so everyone with
+// the same quiz file gets the same daily quiz.
+//
+// Parameters:
+//   - questions: the questions in file order.
+//   - date: the day, as YYYY-MM-DD.
+//   - count: the number of questions to pick.
+//
+// Returns:
+//   - []question: the questions of the day, in the order to ask them.
+func dailyQuestions(questions []question, date string, count int) []question {
+	seed := sha256.Sum256([]byte(date))
+	rng := rand.New(rand.NewPCG(binary.LittleEndian.Uint64(seed[:8]), binary.LittleEndian.Uint64(seed[8:16])))
+	picked := make([]question, 0, min(count, len(questions)))
+	for _, i := range rng.Perm(len(questions))[:cap(picked)] {
+		picked = append(picked, questions[i])
+	}
+	return picked
+}
+
+// writeDailyScores lists everyone's score on the daily quiz of a date, best first.
+func writeDailyScores(entries []historyEntry, date string) {
+	var day []historyEntry
+	for _, e := range entries {
+		if e.Daily == date {
+			day = append(day, e)
+		}
+	}
+	if len(day) == 0 {
+		fmt.Fprintf(stdout, "Nobody has taken the daily quiz of %s yet.\n", date)
+		return
+	}
+	slices.SortStableFunc(day, func(a, b historyEntry) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), a.Time.Compare(b.Time))
+	})
+
+	fmt.Fprintf(stdout, "Daily quiz of %s:\n", date)
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for i, e := range day {
+		fmt.Fprintf(w, "  %d.\t%s\t%d/%d\t%.1f%%\n", i+1, e.User, e.Correct, e.Total, e.Score)
+	}
+	w.Flush()
+}
+
+// runDaily implements the "daily" subcommand.
+//
+// It runs the quiz of the day: a set of questions picked from a quiz by the
+// date alone, so everyone on a team taking the same quiz file gets the same
+// questions in the same order and can compare scores. Each user can take a
+// day's quiz once; -scores lists everyone's scores recorded in the history.
+//
+// The daily pick replaces -shuffle and -limit, including the quiz's own.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, the quiz cannot be
+//     loaded, or the user has already taken the day's quiz.
+func runDaily(ctx context.Context, args []string) error {
+	flags := newFlagSet("daily")
+	opts := registerQuizFlags(flags)
+	count := flags.Int("count", defaultDailyCount, "number of questions in the daily quiz")
+	date := flags.String("date", time.Now().UTC().Format(time.DateOnly), "take or list the daily quiz of `day`, as YYYY-MM-DD (default today, in UTC)")
+	scores := flags.Bool("scores", false, "list everyone's scores on the daily quiz instead of taking it")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *count < 1 {
+		return usageErrorf("usage: go-quiz daily [-count n] [-date day] [-scores] [quiz flags] quiz.csv")
+	}
+	if _, err := time.Parse(time.DateOnly, *date); err != nil {
+		return fmt.Errorf("invalid -date %q: use a date such as 2024-09-01", *date)
+	}
+	filePath, err := filepath.Abs(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error expanding path: %w", err)
+	}
+
+	entries, err := loadHistory(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	if *scores {
+		writeDailyScores(entries, *date)
+		return nil
+	}
+	for _, e := range entries {
+		if e.Daily == *date && e.User == currentUser() {
+			return fmt.Errorf("you have already taken the daily quiz of %s, scoring %.1f%%", *date, e.Score)
+		}
+	}
+
+	settings, err := readQuizSettings(filePath)
+	if err != nil {
+		return err
+	}
+	if err := asParseError(filePath, settings.apply(flags)); err != nil {
+		return err
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if opts.direction == directionMixed {
+		return fmt.Errorf("-direction mixed cannot be used with daily, as everyone must get the same questions")
+	}
+	if opts.load.Indexed {
+		return fmt.Errorf("-indexed cannot be used with daily")
+	}
+
+	questions, headers, err := loadQuiz(filePath, opts.load, slog.Warn)
+	if err != nil {
+		return err
+	}
+	questions = dailyQuestions(applyDirection(questions, opts.direction), *date, *count)
+	if len(questions) == 0 {
+		return fmt.Errorf("no questions to ask")
+	}
+
+	fmt.Fprintf(stdout, "Daily quiz of %s: %d questions, the same for everyone with %s.\n",
+		*date, len(questions), displayPath(filePath))
+	return runSession(ctx, opts, flags, session{
+		quiz:       filePath,
+		questions:  questions,
+		byCategory: columnIndex(headers, "category") >= 0,
+		attempt:    &historyEntry{Daily: *date},
+		title:      fmt.Sprintf("%s, daily quiz of %s", quizTitle(filePath, settings), *date),
+	})
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+// minRewordSimilarity is the share of words two questions with the same
+// answer must have in common to be taken as one question reworded, rather
+// than one removed and another added.
+const minRewordSimilarity = 0.5
+
+// questionChange is a question that is in both versions of a quiz but differs.
+type questionChange struct {
+	Old, New question
+}
+
+// quizDiff holds the differences between two versions of a quiz.
+type quizDiff struct {
+	Added    []question
+	Removed  []question
+	Modified []questionChange
+}
+
+// diffQuestions compares two versions of a quiz.
+//
+// Questions are matched by their text first. Of the rest, a removed and an
+// added question with the same answer and mostly the same words are taken
+// to be one question reworded.
+//
+// Parameters:
+//   - old: the questions of the old version, in file order.
+//   - new: the questions of the new version, in file order.
+//
+// Returns:
+//   - quizDiff: the added, removed and modified questions, each in file order.
+func diffQuestions(old, new []question) quizDiff {
+	matched := make([]bool, len(new))
+	var d quizDiff
+	var unmatched []question
+	for _, o := range old {
+		i := slices.IndexFunc(new, func(n question) bool { return n.Text == o.Text })
+		for i >= 0 && matched[i] {
+			// A duplicate question; look for a later copy.
+			next := slices.IndexFunc(new[i+1:], func(n question) bool { return n.Text == o.Text })
+			if next < 0 {
+				i = -1
+				break
+			}
+			i += 1 + next
+		}
+		if i < 0 {
+			unmatched = append(unmatched, o)
+			continue
+		}
+		matched[i] = true
+		if len(describeChanges(o, new[i])) > 0 {
+			d.Modified = append(d.Modified, questionChange{Old: o, New: new[i]})
+		}
+	}
+
+	for _, o := range unmatched {
+		best, bestSimilarity := -1, minRewordSimilarity
+		for i, n := range new {
+			if matched[i] || n.Answer != o.Answer {
+				continue
+			}
+			if s := wordSimilarity(o.Text, n.Text); s >= bestSimilarity {
+				best, bestSimilarity = i, s
+			}
+		}
+		if best < 0 {
+			d.Removed = append(d.Removed, o)
+			continue
+		}
+		matched[best] = true
+		d.Modified = append(d.Modified, questionChange{Old: o, New: new[best]})
+	}
+
+	for i, n := range new {
+		if !matched[i] {
+			d.Added = append(d.Added, n)
+		}
+	}
+	slices.SortStableFunc(d.Modified, func(a, b questionChange) int { return a.New.Number - b.New.Number })
+	return d
+}
+
+// wordSimilarity returns the share of distinct words, ignoring case, that two
+// texts have in common, from 0 to 1.
+func wordSimilarity(a, b string) float64 {
+	words := func(text string) map[string]bool {
+		set := make(map[string]bool)
+		for _, w := range strings.Fields(strings.ToLower(text)) {
+			set[w] = true
+		}
+		return set
+	}
+	wordsA, wordsB := words(a), words(b)
+	common := 0
+	for w := range wordsB {
+		if wordsA[w] {
+			common++
+		}
+	}
+	union := len(wordsA) + len(wordsB) - common
+	if union == 0 {
+		return 1
+	}
+	return float64(common) / float64(union)
+}
+
+// describeChanges lists how a question differs between two versions, e.g.
+// `answer: "4" -> "5"`. It returns nothing if they are the same.
+func describeChanges(old, new question) []string {
+	var changes []string
+	field := func(name, a, b string) {
+		if a != b {
+			changes = append(changes, fmt.Sprintf("%s: %q -> %q", name, a, b))
+		}
+	}
+	field("question", old.Text, new.Text)
+	field("answer", old.Answer, new.Answer)
+	field("category", old.Category, new.Category)
+	field("section", old.Section, new.Section)
+	field("choices", strings.Join(old.Choices, choiceSeparator), strings.Join(new.Choices, choiceSeparator))
+	field("notes", old.Notes, new.Notes)
+	return changes
+}
+
+// write prints the differences: removed questions marked -, added ones +,
+// and modified ones ~ with what changed, followed by a tally.
+func (d quizDiff) write(w io.Writer, width int) {
+	for _, q := range d.Removed {
+		fmt.Fprintln(w, abbreviate(fmt.Sprintf("- %d. %s (%s)", q.Number, q.Text, q.Answer), width))
+	}
+	for _, q := range d.Added {
+		fmt.Fprintln(w, abbreviate(fmt.Sprintf("+ %d. %s (%s)", q.Number, q.Text, q.Answer), width))
+	}
+	for _, c := range d.Modified {
+		fmt.Fprintln(w, abbreviate(fmt.Sprintf("~ %d. %s", c.New.Number, c.New.Text), width))
+		for _, change := range describeChanges(c.Old, c.New) {
+			fmt.Fprintln(w, abbreviate("    "+change, width))
+		}
+	}
+	fmt.Fprintf(w, "%d added, %d removed, %d modified.\n", len(d.Added), len(d.Removed), len(d.Modified))
+}
+
+// runDiff implements the "diff" subcommand.
+//
+// It reports the questions added to, removed from and modified in a quiz
+// between two versions of its file, so a change to a shared bank can be
+// reviewed before it is accepted. Questions are numbered as in the file
+// they appear in, the new one for modified questions.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or either file cannot be read.
+func runDiff(ctx context.Context, args []string) error {
+	flags := newFlagSet("diff")
+	lang := flags.String("lang", "", "compare the question_<lang> and answer_<lang> columns")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 2 {
+		return usageErrorf("usage: go-quiz diff [-lang code] old.csv new.csv")
+	}
+
+	var versions [2][]question
+	for i := range versions {
+		questions, _, err := loadQuiz(flags.Arg(i), loadOptions{Lang: *lang}, slog.Warn)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", flags.Arg(i), err)
+		}
+		versions[i] = questions
+	}
+
+	diffQuestions(versions[0], versions[1]).write(os.Stdout, terminalWidth())
+	return nil
+}
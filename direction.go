@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// Directions in which -direction asks the questions of a term/definition bank.
+const (
+	directionForward = "forward"
+	directionReverse = "reverse"
+	directionBoth    = "both"
+	directionMixed   = "mixed"
+)
+
+// directions lists the valid -direction values.
+var directions = []string{directionForward, directionReverse, directionBoth, directionMixed}
+
+// validateDirection reports an error if direction is not one of directions.
+func validateDirection(direction string) error {
+	for _, d := range directions {
+		if direction == d {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid -direction %q: use forward, reverse, both or mixed", direction)
+}
+
+// reversed returns the question asked the other way round, giving the answer
+// and expecting the question, e.g. the definition and expecting the term.
+//
+// Choices, tolerances and romanizations belong to the answer, so a reversed
+// question has none.
+func (q question) reversed() question {
+	return question{
+		Number:   q.Number,
+		Text:     q.Answer,
+		Answer:   q.Text,
+		Category: q.Category,
+		Section:  q.Section,
+		Sentence: q.Sentence,
+		Reversed: true,
+	}
+}
+
+// fileText returns the question's text as written in the quiz file, whichever
+// way round it is asked.
+func (q question) fileText() string {
+	if q.Reversed {
+		return q.Answer
+	}
+	return q.Text
+}
+
+// applyDirection turns questions around as -direction asks.
+//
+// Parameters:
+//   - questions: the questions in file order.
+//   - direction: forward asks them as written, reverse asks every one the
+//     other way round, both asks each both ways (all forward ones first, so
+//     use -shuffle to mix them) and mixed picks a way at random for each.
+//
+// Returns:
+//   - []question: the questions to ask, in file order.
+func applyDirection(questions []question, direction string) []question {
+	switch direction {
+	case directionReverse:
+		for i, q := range questions {
+			questions[i] = q.reversed()
+		}
+	case directionBoth:
+		for _, q := range questions {
+			questions = append(questions, q.reversed())
+		}
+	case directionMixed:
+		for i, q := range questions {
+			if rand.IntN(2) == 1 {
+				questions[i] = q.reversed()
+			}
+		}
+	}
+	return questions
+}
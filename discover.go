@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxRecentQuizzes is the number of recently used quizzes offered when choosing a file.
+const maxRecentQuizzes = 5
+
+// quizSearchDirs returns the directories searched for quiz files: ./data, the
+// user's data directory ($XDG_DATA_HOME/go-quiz, ~/.local/share/go-quiz by
+// default) and go-quiz under each of the system data directories in $XDG_DATA_DIRS.
+func quizSearchDirs() []string {
+	dirs := []string{"data"}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+	}
+	if dataHome != "" {
+		dirs = append(dirs, filepath.Join(dataHome, "go-quiz"))
+	}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		if dir != "" {
+			dirs = append(dirs, filepath.Join(dir, "go-quiz"))
+		}
+	}
+	return dirs
+}
+
+// isQuizFile reports whether a file name has the extension of a supported quiz format.
+func isQuizFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".csv" || isWorkbook(name) || isTextQuiz(name)
+}
+
+// discoverQuizzes returns the absolute paths of the quiz files in the search
+// directories, in directory order and sorted by name within each directory.
+// Directories that do not exist or cannot be read are skipped.
+func discoverQuizzes() []string {
+	var found []string
+	for _, dir := range quizSearchDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && isQuizFile(entry.Name()) {
+				if abs, err := filepath.Abs(filepath.Join(dir, entry.Name())); err == nil {
+					found = append(found, abs)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// recentQuizzes returns up to limit quizzes from the history, most recently
+// attempted first, leaving out files that no longer exist.
+func recentQuizzes(ctx context.Context, limit int) []string {
+	entries, err := loadHistory(ctx, "")
+	if err != nil {
+		return nil
+	}
+
+	var recent []string
+	for i := len(entries) - 1; i >= 0 && len(recent) < limit; i-- {
+		quiz := entries[i].Quiz
+		if slices.Contains(recent, quiz) {
+			continue
+		}
+		if _, err := os.Stat(quiz); err == nil {
+			recent = append(recent, quiz)
+		}
+	}
+	return recent
+}
+
+// quizChoices returns the quizzes offered when no file is given: the recently
+// used ones first, then the others found in the search directories.
+//
+// Returns:
+//   - []string: the absolute paths of the quizzes, without duplicates.
+//   - int: how many of them, from the start, are recently used.
+func quizChoices(ctx context.Context) ([]string, int) {
+	choices := recentQuizzes(ctx, maxRecentQuizzes)
+	recent := len(choices)
+	for _, quiz := range discoverQuizzes() {
+		if !slices.Contains(choices, quiz) {
+			choices = append(choices, quiz)
+		}
+	}
+	return choices, recent
+}
+
+// displayPath shortens an absolute path for display: relative to the working
+// directory when inside it, or with the home directory written as ~.
+func displayPath(path string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, path); err == nil && filepath.IsLocal(rel) {
+			return rel
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, path); err == nil && filepath.IsLocal(rel) {
+			return filepath.Join("~", rel)
+		}
+	}
+	return path
+}
+
+// quizInfo is what the quiz menu shows about a quiz besides its path.
+type quizInfo struct {
+	// Title is the title set in the quiz file, if any.
+	Title string
+	// Questions is the number of questions, or -1 if the file cannot be read.
+	Questions int
+	// LastScore is the score of the latest recorded attempt, if Attempted.
+	LastScore float64
+	Attempted bool
+}
+
+// describeQuizzes reads the title and question count of each quiz and looks
+// up its latest score in the history.
+func describeQuizzes(ctx context.Context, paths []string) []quizInfo {
+	history, _ := loadHistory(ctx, "")
+	latest := make(map[string]float64)
+	for _, entry := range history {
+		latest[entry.Quiz] = entry.Score
+	}
+
+	infos := make([]quizInfo, len(paths))
+	for i, path := range paths {
+		infos[i].Questions = -1
+		if questions, _, err := loadQuiz(path, loadOptions{}, ignoreWarnings); err == nil {
+			infos[i].Questions = len(questions)
+		}
+		if settings, err := readQuizSettings(path); err == nil {
+			infos[i].Title = settings.Title
+		}
+		infos[i].LastScore, infos[i].Attempted = latest[path]
+	}
+	return infos
+}
+
+// writeQuizMenu lists quizzes as a numbered menu with their titles, question
+// counts and latest scores, marking the first recent ones as recently used.
+func writeQuizMenu(ctx context.Context, w io.Writer, paths []string, recent int) {
+	infos := describeQuizzes(ctx, paths)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, path := range paths {
+		count := "unreadable"
+		if infos[i].Questions >= 0 {
+			count = fmt.Sprintf("%d questions", infos[i].Questions)
+		}
+		score := "not attempted"
+		if infos[i].Attempted {
+			score = fmt.Sprintf("last %.1f%%", infos[i].LastScore)
+		}
+		name := displayPath(path)
+		if infos[i].Title != "" {
+			name = fmt.Sprintf("%s (%s)", infos[i].Title, name)
+		}
+		if i < recent {
+			name += " (recent)"
+		}
+		fmt.Fprintf(tw, "  %d)\t%s\t%s\t%s\n", i+1, name, count, score)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,697 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultDuelAddress is the address a duel is hosted on by default.
+const defaultDuelAddress = ":7700"
+
+// duelDialTimeout bounds the time taken to connect to a duel's host.
+const duelDialTimeout = 10 * time.Second
+
+// duelHelloTimeout bounds the time a player connecting to a game has to say
+// who they are, so a connection that never does cannot hold up the others.
+const duelHelloTimeout = 10 * time.Second
+
+// Types of the messages of the duel protocol.
+const (
+	// duelHello is sent by a player joining, with their name.
+	duelHello = "hello"
+	// duelStart starts a match, with the opponent's name, the questions and
+	// the time allowed per question.
+	duelStart = "start"
+	// duelAnswer is sent by a player who joined with each answer they give
+	// or revise, for the host to grade.
+	duelAnswer = "answer"
+	// duelVerdict is the host's grading of an answer, with its credit.
+	duelVerdict = "verdict"
+	// duelProgress is a player's progress after each answer, sent to the
+	// opponent.
+	duelProgress = "progress"
+	// duelDone is sent by a player who joined after their last answer, and
+	// to the opponent with the player's score.
+	duelDone = "done"
+	// duelResult ends a match, with the winner's name and the outcome.
+	duelResult = "result"
+	// duelNotice is text to show a player, such as news of a tournament.
+	duelNotice = "notice"
+	// duelEnd ends the game, with any closing words.
+	duelEnd = "end"
+	// duelError ends the game with the reason.
+	duelError = "error"
+)
+
+// duelMessage is a message of the duel protocol. Players exchange them as
+// JSON objects, one per line, over a TCP connection.
+type duelMessage struct {
+	Type string `json:"type"`
+	// Name is the sender's name, or the opponent's in start messages and the
+	// winner's in result messages (empty for a draw).
+	Name      string         `json:"name,omitempty"`
+	Questions []duelQuestion `json:"questions,omitempty"`
+	Timeout   time.Duration  `json:"timeout,omitempty"`
+	// Handicap is the player's own handicap in start messages, and the
+	// sender's in progress and done messages.
+	Handicap handicap `json:"handicap"`
+	// Brief leaves out the score at the end of a match, in start messages,
+	// for matches of a question or two.
+	Brief bool `json:"brief,omitempty"`
+	// Number is the position of the question answered in answer and
+	// verdict messages, counting from 1, and Answer the answer given.
+	Number int    `json:"number,omitempty"`
+	Answer string `json:"answer,omitempty"`
+	// Answered, Correct, Points and Elapsed are a player's progress, and
+	// Total the number of questions, in progress and done messages, as
+	// scored by the host (see duelScore). Points and Elapsed count the
+	// handicap. Points is the credit of the answer in verdict messages.
+	Answered int           `json:"answered,omitempty"`
+	Correct  int           `json:"correct,omitempty"`
+	Points   float64       `json:"points,omitempty"`
+	Total    int           `json:"total,omitempty"`
+	Elapsed  time.Duration `json:"elapsed,omitempty"`
+	// Text is the outcome in result messages, the text of notices and the
+	// reason in error messages. In start messages, it is shown instead of
+	// the opponent's name for games of more than two players.
+	Text string `json:"text,omitempty"`
+}
+
+// duelQuestion is a question as sent to the players of a hosted game:
+// without its answer, or anything else giving it away, as the host grades
+// the answers (see refereePlayer). Hints are not sent either.
+type duelQuestion struct {
+	// Number is the question's position in the match, counting from 1, by
+	// which its answer is sent.
+	Number    int      `json:"number"`
+	Text      string   `json:"text"`
+	Choices   []string `json:"choices,omitempty"`
+	Category  string   `json:"category,omitempty"`
+	Section   string   `json:"section,omitempty"`
+	Revisions string   `json:"revisions,omitempty"`
+}
+
+// newDuelQuestions returns the questions of a match as sent to the players.
+func newDuelQuestions(questions []question) []duelQuestion {
+	sent := make([]duelQuestion, len(questions))
+	for i, q := range questions {
+		sent[i] = duelQuestion{
+			Number:    i + 1,
+			Text:      q.Text,
+			Choices:   q.Choices,
+			Category:  q.Category,
+			Section:   q.Section,
+			Revisions: q.Revisions,
+		}
+	}
+	return sent
+}
+
+// question returns the question to ask, which has no answer to grade by.
+func (q duelQuestion) question() question {
+	return question{
+		Number:    q.Number,
+		Text:      q.Text,
+		Choices:   q.Choices,
+		Category:  q.Category,
+		Section:   q.Section,
+		Revisions: q.Revisions,
+	}
+}
+
+// duelConn is a connection to the other side of a duel.
+type duelConn struct {
+	conn     net.Conn
+	enc      *json.Encoder
+	mu       sync.Mutex
+	messages chan duelMessage
+	// err is the reason the connection closed, set before messages is closed.
+	err error
+}
+
+// newDuelConn starts reading the messages of a connection, until it closes
+// or ctx is done, which closes it.
+//
+// Parameters:
+//   - ctx: the context of the game.
+//   - conn: the connection.
+//   - live: if set, is called with each message as it arrives, whatever
+//     the player is doing, to show it; progress and notices then go no
+//     further. The other messages are kept for receive.
+//
+// Returns:
+//   - *duelConn: the connection, ready to send and receive messages.
+func newDuelConn(ctx context.Context, conn net.Conn, live func(m duelMessage)) *duelConn {
+	d := &duelConn{conn: conn, enc: json.NewEncoder(conn), messages: make(chan duelMessage, 8)}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	go func() {
+		defer close(d.messages)
+		defer stop()
+		dec := json.NewDecoder(conn)
+		for {
+			var m duelMessage
+			if err := dec.Decode(&m); err != nil {
+				d.err = err
+				if ctx.Err() != nil {
+					d.err = ctx.Err()
+				}
+				return
+			}
+			if live != nil {
+				// Messages are shown here, rather than as they are received,
+				// so they are shown in the order they came.
+				live(m)
+				if m.Type == duelProgress || m.Type == duelNotice {
+					continue
+				}
+			}
+			d.messages <- m
+		}
+	}()
+	return d
+}
+
+// showDuelMessages returns the function showing a player the messages of
+// their game (see newDuelConn).
+func showDuelMessages(name string) func(m duelMessage) {
+	return func(m duelMessage) {
+		switch m.Type {
+		case duelStart:
+			if m.Text != "" {
+				fmt.Fprintln(stdout, m.Text)
+			} else {
+				fmt.Fprintf(stdout, "Duel against %s: %d questions. Go!\n", m.Name, len(m.Questions))
+			}
+			if m.Handicap != (handicap{}) {
+				fmt.Fprintf(stdout, "Your handicap is %s.\n", m.Handicap)
+			}
+		case duelResult:
+			writeDuelResult(m, name)
+		case duelEnd:
+			if m.Text != "" {
+				fmt.Fprintln(stdout, m.Text)
+			}
+		default:
+			showDuelMessage(m)
+		}
+	}
+}
+
+// showDuelMessage shows the player the opponent's progress and notices.
+func showDuelMessage(m duelMessage) {
+	switch m.Type {
+	case duelProgress:
+		if m.Handicap.Multiplier != 0 {
+			fmt.Fprintf(stdout, "  » %s: %d/%d answered, %d correct, %s with handicap %s\n",
+				m.Name, m.Answered, m.Total, m.Correct, formatPoints(m.Points), m.Handicap)
+			return
+		}
+		fmt.Fprintf(stdout, "  » %s: %d/%d answered, %d correct\n", m.Name, m.Answered, m.Total, m.Correct)
+	case duelDone:
+		fmt.Fprintf(stdout, "  » %s has finished: %s in %s\n", m.Name, formatPoints(m.Points), formatElapsed(m.Elapsed))
+	case duelNotice:
+		fmt.Fprintln(stdout, m.Text)
+	}
+}
+
+// send sends a message to the other side. It is safe to call from several
+// goroutines.
+func (d *duelConn) send(m duelMessage) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.enc.Encode(m); err != nil {
+		return fmt.Errorf("error sending to the other player: %w", err)
+	}
+	return nil
+}
+
+// receive waits for the next message of one of the given types, giving up
+// with ctx's error if ctx is done first. Done messages, which have already
+// been shown, are skipped when waiting for other types, and an error message
+// from the other side is returned as an error.
+func (d *duelConn) receive(ctx context.Context, kinds ...string) (duelMessage, error) {
+	for {
+		var m duelMessage
+		var ok bool
+		select {
+		case m, ok = <-d.messages:
+		case <-ctx.Done():
+			return duelMessage{}, ctx.Err()
+		}
+		switch {
+		case !ok:
+			return duelMessage{}, fmt.Errorf("the connection to the other player was lost: %w", d.err)
+		case slices.Contains(kinds, m.Type):
+			return m, nil
+		case m.Type == duelError:
+			return m, errors.New(m.Text)
+		case m.Type != duelDone:
+			return m, fmt.Errorf("unexpected %q message from the other player", m.Type)
+		}
+	}
+}
+
+// receiveHello waits for the hello message of a player who has just
+// connected, for up to duelHelloTimeout.
+func (d *duelConn) receiveHello(ctx context.Context) (duelMessage, error) {
+	helloCtx, cancel := context.WithTimeout(ctx, duelHelloTimeout)
+	defer cancel()
+	hello, err := d.receive(helloCtx, duelHello)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return hello, fmt.Errorf("%s connected but did not join within %s", d.conn.RemoteAddr(), duelHelloTimeout)
+	}
+	return hello, err
+}
+
+// accept waits for a connection on listener, giving up with ctx's error if
+// ctx is done first, which closes the listener.
+func accept(ctx context.Context, listener net.Listener) (net.Conn, error) {
+	stop := context.AfterFunc(ctx, func() { listener.Close() })
+	defer stop()
+	conn, err := listener.Accept()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return conn, err
+}
+
+// joinCommands returns the commands players run to join a game hosted on
+// addr, one for each address of this machine they may reach it on: those of
+// its network interfaces, other than loopback and link-local ones, when addr
+// is on all of them.
+func joinCommands(addr net.Addr) []string {
+	tcp := addr.(*net.TCPAddr)
+	ips := []net.IP{tcp.IP}
+	if tcp.IP.IsUnspecified() {
+		ips = nil
+		addrs, err := net.InterfaceAddrs()
+		if err != nil {
+			slog.Warn(fmt.Sprintf("error listing network addresses: %v", err))
+		}
+		for _, a := range addrs {
+			if ip, ok := a.(*net.IPNet); ok && !ip.IP.IsLoopback() && !ip.IP.IsLinkLocalUnicast() {
+				ips = append(ips, ip.IP)
+			}
+		}
+		if len(ips) == 0 {
+			ips = []net.IP{net.IPv4(127, 0, 0, 1)}
+		}
+	}
+	commands := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		commands = append(commands, "go-quiz duel -join "+net.JoinHostPort(ip.String(), strconv.Itoa(tcp.Port)))
+	}
+	return commands
+}
+
+// writeWaiting tells the host who a game on listener is waiting for and how
+// they join it.
+func writeWaiting(listener net.Listener, who string) {
+	fmt.Fprintf(stdout, "Waiting for %s on %s. To join, run one of:\n", who, listener.Addr())
+	for _, command := range joinCommands(listener.Addr()) {
+		fmt.Fprintf(stdout, "  %s\n", command)
+	}
+}
+
+// formatPoints formats a number of points, without a fraction if it is whole.
+func formatPoints(points float64) string {
+	if points == 1 {
+		return "1 point"
+	}
+	return fmt.Sprintf("%.4g points", points)
+}
+
+// formatElapsed formats a player's time to a tenth of a second.
+func formatElapsed(d time.Duration) string {
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// playDuel asks the questions of a match at the player's terminal and
+// shows them their score at the end, unless the match is brief.
+//
+// Parameters:
+//   - ctx: the context; the match ends early when it is done.
+//   - opts: the quiz flags.
+//   - filePath: the quiz the questions come from, to save the questions
+//     reported with /report; empty if the player does not have it.
+//   - start: the start message of the match, with the time limit and the
+//     player's handicap.
+//   - questions: the questions of the match.
+//   - setup: prepares the engine, e.g. to send each answer as it is given.
+//
+// Returns:
+//   - []response: the player's responses.
+func playDuel(ctx context.Context, opts *quizOptions, filePath string, start duelMessage, questions []question, setup func(eng *engine)) []response {
+	ask := opts.ask
+	ask.Timeout = start.Timeout
+	if ask.Timeout > 0 {
+		ask.Timeout += start.Handicap.ExtraTime
+	}
+	eng := newTerminalEngine(ask)
+	setup(eng)
+
+	responses := eng.run(ctx, questions)
+	if filePath == "" && len(eng.reports) > 0 {
+		slog.Warn("reports on the host's questions are not saved")
+	} else if err := saveReports(ctx, filePath, eng.reports); err != nil {
+		slog.Warn(err.Error())
+	}
+	if !start.Brief {
+		summarize(responses, eng.scoredTotal(len(questions), responses), false).write(stdout, eng.columns())
+	}
+	if opts.results != "" && !eng.discard {
+		if err := writeResults(opts.results, responses); err != nil {
+			slog.Warn(err.Error())
+		}
+	}
+	return responses
+}
+
+// duelScore scores a player's responses in a match, counting their
+// handicap.
+//
+// Parameters:
+//   - kind: the type of the message: duelProgress or duelDone.
+//   - name: the player's name.
+//   - h: the player's handicap.
+//   - total: the number of questions of the match.
+//   - responses: the player's responses so far.
+//   - elapsed: the time since the match started, by the host's clock.
+//
+// Returns:
+//   - duelMessage: the message with the player's score.
+func duelScore(kind, name string, h handicap, total int, responses []response, elapsed time.Duration) duelMessage {
+	return duelMessage{
+		Type:     kind,
+		Name:     name,
+		Answered: len(responses),
+		Correct:  calculateScore(responses),
+		Points:   h.points(calculatePoints(responses)),
+		Total:    total,
+		Elapsed:  h.elapsed(elapsed, len(responses)),
+		Handicap: h,
+	}
+}
+
+// refereePlayer grades the answers of a player who joined a match as they
+// come, until the player is done or gone. The answers are graded against
+// the host's questions and timed by the host's clock, so the score does not
+// depend on what the player's program claims.
+//
+// Parameters:
+//   - d: the connection to the player, whose match has just started.
+//   - name: the player's name.
+//   - questions: the questions of the match, with their answers.
+//   - h: the player's handicap.
+//   - relay: if set, is called with the player's progress after each
+//     answer and their score once done, e.g. to show them to the opponent.
+//
+// Returns:
+//   - *duelMessage: the player's score, as a done message, or nil if they
+//     left the match or answered a question not in it.
+func refereePlayer(d *duelConn, name string, questions []question, h handicap, relay func(m duelMessage)) *duelMessage {
+	began := time.Now()
+	responses := make([]*response, len(questions))
+	score := func(kind string) duelMessage {
+		var given []response
+		for _, r := range responses {
+			if r != nil {
+				given = append(given, *r)
+			}
+		}
+		return duelScore(kind, name, h, len(questions), given, time.Since(began))
+	}
+
+	for m := range d.messages {
+		switch m.Type {
+		case duelAnswer:
+			i := m.Number - 1
+			if i < 0 || i >= len(questions) {
+				d.send(duelMessage{Type: duelError, Text: fmt.Sprintf("there is no question %d in this match", m.Number)})
+				d.conn.Close()
+				return nil
+			}
+			revised := responses[i] != nil
+			responses[i] = &response{Question: questions[i], Answer: m.Answer}
+			// A lost connection shows as the player leaving.
+			d.send(duelMessage{Type: duelVerdict, Number: m.Number, Points: responses[i].credit()})
+			if !revised && relay != nil {
+				relay(score(duelProgress))
+			}
+		case duelDone:
+			done := score(duelDone)
+			if relay != nil {
+				relay(done)
+			}
+			return &done
+		}
+	}
+	return nil
+}
+
+// decideDuel decides a match from the players' scores: the most points wins,
+// and between equal points the faster player.
+//
+// Returns:
+//   - string: the name of the winner, or empty for a draw.
+//   - string: the outcome, as shown to both players.
+func decideDuel(a, b duelMessage) (string, string) {
+	if b.Points > a.Points || (b.Points == a.Points && b.Elapsed < a.Elapsed) {
+		a, b = b, a
+	}
+	switch {
+	case a.Points != b.Points:
+		return a.Name, fmt.Sprintf("%s wins with %s to %s.", a.Name, formatPoints(a.Points), formatPoints(b.Points))
+	case a.Elapsed != b.Elapsed:
+		return a.Name, fmt.Sprintf("%s wins on time: %s each, in %s to %s.",
+			a.Name, formatPoints(a.Points), formatElapsed(a.Elapsed), formatElapsed(b.Elapsed))
+	}
+	return "", fmt.Sprintf("Draw: %s each, in the same time.", formatPoints(a.Points))
+}
+
+// writeDuelResult shows the outcome of a match to a player.
+func writeDuelResult(result duelMessage, name string) {
+	fmt.Fprintln(stdout, "\n"+result.Text)
+	switch result.Name {
+	case name:
+		fmt.Fprintln(stdout, "You won!")
+	case "":
+	default:
+		fmt.Fprintln(stdout, "You lost.")
+	}
+}
+
+// runDuel implements the "duel" subcommand.
+//
+// Two players race through the same questions, each at their own terminal,
+// and see each other's progress as they go. One player hosts the duel with a
+// quiz, picking the questions with the quiz flags; the other joins with
+// -join, given the host's address. The player with the most points wins, and between equal
+// points the faster one. Duels are not recorded in the history.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, the quiz cannot be
+//     loaded, or the connection to the other player fails.
+func runDuel(ctx context.Context, args []string) error {
+	flags := newFlagSet("duel")
+	opts := registerQuizFlags(flags)
+	listen := flags.String("listen", defaultDuelAddress, "host the duel on `address`")
+	join := flags.String("join", "", "join the duel hosted on `address`, e.g. example.com:7700")
+	name := flags.String("name", currentUser(), "your `name`, as shown to the other player")
+	handicapList := flags.String("handicap", "", "as the host, give players `handicaps`: point multipliers, extra time per question or both, e.g. alice=x1.5,bob=+10s")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if (*join == "" && flags.NArg() != 1) || (*join != "" && flags.NArg() != 0) {
+		return usageErrorf("usage: go-quiz duel [-listen address] [-name name] [quiz flags] quiz.csv, or go-quiz duel -join address [-name name]")
+	}
+	if *name == "" {
+		return fmt.Errorf("-name cannot be empty")
+	}
+	handicaps, err := parseHandicaps(*handicapList)
+	if err != nil {
+		return err
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if *join != "" && len(handicaps) > 0 {
+		return fmt.Errorf("-handicap is for the host of the duel")
+	}
+	if *join != "" && opts.ask.Spelling {
+		return fmt.Errorf("-spelling cannot be used to join a duel, as the answers stay with the host")
+	}
+	stopRecording, err := opts.startInput()
+	if err != nil {
+		return err
+	}
+	defer stopRecording()
+	if *join != "" {
+		return joinDuel(ctx, *join, opts, *name)
+	}
+
+	filePath, err := filepath.Abs(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error expanding path: %w", err)
+	}
+	settings, err := readQuizSettings(filePath)
+	if err != nil {
+		return err
+	}
+	if err := asParseError(filePath, settings.apply(flags)); err != nil {
+		return err
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	questions, err := duelQuestions(filePath, opts, settings.Constraints)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fmt.Errorf("error hosting duel: %w", err)
+	}
+	writeWaiting(listener, "an opponent")
+	conn, err := accept(ctx, listener)
+	listener.Close()
+	if err != nil {
+		return fmt.Errorf("error accepting opponent: %w", err)
+	}
+	defer conn.Close()
+
+	d := newDuelConn(ctx, conn, nil)
+	hello, err := d.receiveHello(ctx)
+	if err != nil {
+		return err
+	}
+	if hello.Name == *name {
+		d.send(duelMessage{Type: duelError, Text: fmt.Sprintf("both players are called %s: join with another -name", *name)})
+		return fmt.Errorf("both players are called %s", *name)
+	}
+	warnUnknownHandicaps(handicaps, []string{*name, hello.Name})
+	start := duelMessage{Type: duelStart, Name: *name, Questions: newDuelQuestions(questions), Timeout: opts.ask.Timeout, Handicap: handicaps[hello.Name]}
+	if err := d.send(start); err != nil {
+		return err
+	}
+	began := time.Now()
+	refereed := make(chan *duelMessage, 1)
+	go func() {
+		refereed <- refereePlayer(d, hello.Name, questions, handicaps[hello.Name], showDuelMessage)
+	}()
+
+	fmt.Fprintf(stdout, "Duel against %s: %d questions. Go!\n", hello.Name, len(questions))
+	h := handicaps[*name]
+	if h != (handicap{}) {
+		fmt.Fprintf(stdout, "Your handicap is %s.\n", h)
+	}
+	start.Handicap = h
+	var given []response
+	responses := playDuel(ctx, opts, filePath, start, questions, func(eng *engine) {
+		eng.answered = func(r response) {
+			given = append(given, r)
+			// A lost connection shows when waiting for the outcome.
+			d.send(duelScore(duelProgress, *name, h, len(questions), given, time.Since(began)))
+		}
+	})
+	own := duelScore(duelDone, *name, h, len(questions), responses, time.Since(began))
+	if err := d.send(own); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Waiting for %s to finish...\n", hello.Name)
+	theirs := <-refereed
+	if theirs == nil {
+		return fmt.Errorf("the connection to the other player was lost: %w", d.err)
+	}
+	winner, text := decideDuel(own, *theirs)
+	slog.Info("duel played", "winner", winner, "outcome", text)
+	result := duelMessage{Type: duelResult, Name: winner, Text: text}
+	writeDuelResult(result, *name)
+	if err := d.send(result); err != nil {
+		return err
+	}
+	return d.send(duelMessage{Type: duelEnd})
+}
+
+// duelQuestions loads and picks the questions of a match from a quiz, as a
+// session of the quiz would with the quiz flags and the shuffle constraints
+// given. Only the host has them: the players are sent them without their
+// answers (see newDuelQuestions).
+func duelQuestions(filePath string, opts *quizOptions, constraints shuffleConstraints) ([]question, error) {
+	questions, _, err := loadQuiz(filePath, opts.load, slog.Warn)
+	if err != nil {
+		return nil, err
+	}
+	if opts.load.Indexed {
+		constraints = shuffleConstraints{}
+	}
+	questions = selectQuestions(applyDirection(questions, opts.direction), opts.load.Shuffle, constraints, opts.load.Limit)
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("no questions to ask")
+	}
+	return questions, nil
+}
+
+// joinDuel joins the duel or tournament hosted on an address and plays its
+// matches, with the questions and time limits chosen by the host, until the
+// host ends the game. The questions come without their answers: each answer
+// is sent to the host, who grades it.
+func joinDuel(ctx context.Context, address string, opts *quizOptions, name string) error {
+	dialer := net.Dialer{Timeout: duelDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("error joining duel: %w", err)
+	}
+	defer conn.Close()
+
+	d := newDuelConn(ctx, conn, showDuelMessages(name))
+	if err := d.send(duelMessage{Type: duelHello, Name: name}); err != nil {
+		return err
+	}
+	for {
+		start, err := d.receive(ctx, duelStart, duelEnd)
+		if err != nil {
+			return err
+		}
+		if start.Type == duelEnd {
+			return nil
+		}
+		questions := make([]question, len(start.Questions))
+		for i, q := range start.Questions {
+			questions[i] = q.question()
+		}
+		playDuel(ctx, opts, "", start, questions, func(eng *engine) {
+			eng.grade = func(r response) (float64, error) {
+				if err := d.send(duelMessage{Type: duelAnswer, Number: r.Question.Number, Answer: r.Answer}); err != nil {
+					return 0, err
+				}
+				verdict, err := d.receive(ctx, duelVerdict)
+				if err != nil {
+					return 0, err
+				}
+				return verdict.Points, nil
+			}
+		})
+		if err := d.send(duelMessage{Type: duelDone, Name: name}); err != nil {
+			return err
+		}
+		if _, err := d.receive(ctx, duelResult); err != nil {
+			return err
+		}
+	}
+}
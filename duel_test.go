@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRefereePlayer(t *testing.T) {
+	questions := []question{
+		{Text: "2+2", Answer: "4"},
+		{Text: "Capital of France", Answer: "Paris"},
+		{Text: "3+3", Answer: "6"},
+	}
+	tests := []struct {
+		name     string
+		answers  []duelMessage
+		verdicts []float64
+		correct  int
+		points   float64
+	}{
+		{
+			name: "answers graded by the host",
+			answers: []duelMessage{
+				{Type: duelAnswer, Number: 1, Answer: "4"},
+				{Type: duelAnswer, Number: 2, Answer: "Lyon"},
+				{Type: duelAnswer, Number: 3, Answer: "6"},
+			},
+			verdicts: []float64{1, 0, 1},
+			correct:  2,
+			points:   2,
+		},
+		{
+			name: "revised answer replaces the first",
+			answers: []duelMessage{
+				{Type: duelAnswer, Number: 1, Answer: "5"},
+				{Type: duelAnswer, Number: 2, Answer: "Paris"},
+				{Type: duelAnswer, Number: 1, Answer: "4"},
+			},
+			verdicts: []float64{0, 1, 1},
+			correct:  2,
+			points:   2,
+		},
+		{
+			name: "score claimed by the player ignored",
+			answers: []duelMessage{
+				{Type: duelAnswer, Number: 1, Answer: "5"},
+				{Type: duelProgress, Answered: 3, Correct: 3, Points: 3},
+			},
+			verdicts: []float64{0},
+			correct:  0,
+			points:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, player := net.Pipe()
+			defer player.Close()
+			d := newDuelConn(context.Background(), host, nil)
+
+			var verdicts []float64
+			sent := make(chan struct{})
+			go func() {
+				defer close(sent)
+				enc := json.NewEncoder(player)
+				dec := json.NewDecoder(player)
+				for _, m := range tt.answers {
+					enc.Encode(m)
+					if m.Type == duelAnswer {
+						var verdict duelMessage
+						dec.Decode(&verdict)
+						verdicts = append(verdicts, verdict.Points)
+					}
+				}
+				enc.Encode(duelMessage{Type: duelDone, Points: 100})
+			}()
+
+			done := refereePlayer(d, "bob", questions, handicap{}, nil)
+			<-sent
+			if !slices.Equal(verdicts, tt.verdicts) {
+				t.Errorf("verdicts = %v, want %v", verdicts, tt.verdicts)
+			}
+			if done == nil {
+				t.Fatal("refereePlayer returned no score")
+			}
+			if done.Correct != tt.correct || done.Points != tt.points {
+				t.Errorf("score = %d correct, %g points; want %d, %g", done.Correct, done.Points, tt.correct, tt.points)
+			}
+			if done.Name != "bob" || done.Total != len(questions) {
+				t.Errorf("done = %+v, want bob's score out of %d", done, len(questions))
+			}
+		})
+	}
+}
+
+func TestDecideDuel(t *testing.T) {
+	score := func(name string, points float64, elapsed time.Duration) duelMessage {
+		return duelMessage{Type: duelDone, Name: name, Points: points, Elapsed: elapsed}
+	}
+	tests := []struct {
+		name   string
+		a, b   duelMessage
+		winner string
+		text   string
+	}{
+		{
+			name:   "first has more points",
+			a:      score("alice", 3, 9*time.Second),
+			b:      score("bob", 2, time.Second),
+			winner: "alice",
+			text:   "alice wins with 3 points to 2 points.",
+		},
+		{
+			name:   "second has more points",
+			a:      score("alice", 1, time.Second),
+			b:      score("bob", 1.5, 9*time.Second),
+			winner: "bob",
+			text:   "bob wins with 1.5 points to 1 point.",
+		},
+		{
+			name:   "faster on equal points",
+			a:      score("alice", 2, 2400*time.Millisecond),
+			b:      score("bob", 2, 2300*time.Millisecond),
+			winner: "bob",
+			text:   "bob wins on time: 2 points each, in 2.3s to 2.4s.",
+		},
+		{
+			name:   "draw",
+			a:      score("alice", 0, time.Second),
+			b:      score("bob", 0, time.Second),
+			winner: "",
+			text:   "Draw: 0 points each, in the same time.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			winner, text := decideDuel(tt.a, tt.b)
+			if winner != tt.winner || text != tt.text {
+				t.Errorf("decideDuel = %q, %q; want %q, %q", winner, text, tt.winner, tt.text)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// endSuddenDeath ends a quiz taken with SuddenDeath at a wrong answer, the
+// last of the responses. Only graded questions are counted: warm-up and
+// cool-down answers neither end the quiz nor count as correct.
+func (e *engine) endSuddenDeath(responses []response, questions []question) {
+	answered := len(gradedResponses(responses))
+	fmt.Fprintf(e.out, "\nSudden death: wrong answer at question %d of %d, after %d correct.\n", answered, countGraded(questions), answered-1)
+}
+
+// roundAnswer is a player's answer in a round of an elimination game.
+type roundAnswer struct {
+	player *tournamentPlayer
+	// done is the player's score, or nil if they left.
+	done *duelMessage
+}
+
+// playRound asks every player the same question and returns their answers,
+// graded by the host (see refereePlayer), once all of them have answered or
+// left. Each answer is passed on to the other players as it comes.
+func playRound(players []*tournamentPlayer, q question, timeout time.Duration, text string) []roundAnswer {
+	answers := make(chan roundAnswer)
+	for _, p := range players {
+		go func() {
+			a := roundAnswer{player: p}
+			start := duelMessage{Type: duelStart, Questions: newDuelQuestions([]question{q}), Timeout: timeout, Brief: true, Text: text}
+			if p.d.send(start) == nil {
+				a.done = refereePlayer(p.d, p.name, []question{q}, handicap{}, nil)
+			}
+			answers <- a
+		}()
+	}
+
+	round := make([]roundAnswer, 0, len(players))
+	for range players {
+		a := <-answers
+		if a.done != nil {
+			for _, p := range players {
+				if p != a.player {
+					p.d.send(*a.done)
+				}
+			}
+		}
+		round = append(round, a)
+	}
+	return round
+}
+
+// eliminate decides who is out after a round of an elimination game, from
+// the answers as graded by the host: those who missed the question, unless
+// everyone did, in which case nobody is. Players who left are in neither
+// list.
+//
+// Parameters:
+//   - players: the players of the round, in the order they joined.
+//   - round: the players' answers.
+//
+// Returns:
+//   - []*tournamentPlayer: the players left, in the order they joined.
+//   - []*tournamentPlayer: the players out.
+func eliminate(players []*tournamentPlayer, round []roundAnswer) (left, out []*tournamentPlayer) {
+	for _, a := range round {
+		switch {
+		case a.done == nil:
+		case a.done.Correct == 1:
+			left = append(left, a.player)
+		default:
+			out = append(out, a.player)
+		}
+	}
+	if len(left) == 0 {
+		// Everyone missed the question, so everyone still playing stays in.
+		left, out = out, nil
+	}
+	// Keep the players in the order they joined.
+	slices.SortStableFunc(left, func(a, b *tournamentPlayer) int {
+		return slices.Index(players, a) - slices.Index(players, b)
+	})
+	return left, out
+}
+
+// runElimination implements the "elimination" subcommand.
+//
+// It hosts an elimination game for players joining with go-quiz duel -join.
+// Every round, all the players left are asked the same question, and those
+// who miss it are out, unless everyone does, in which case nobody is (see
+// eliminate). The host grades the answers, the players being sent the
+// questions without them. The
+// last player left wins. If the questions run out first, the player left
+// with the least time spent answering wins.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, the quiz cannot be
+//     loaded or the players cannot join; ctx's error if the game is
+//     interrupted, with no winner announced.
+func runElimination(ctx context.Context, args []string) error {
+	flags := newFlagSet("elimination")
+	opts := registerQuizFlags(flags)
+	listen := flags.String("listen", defaultDuelAddress, "host the game on `address`")
+	count := flags.Int("players", 4, "number of players to wait for")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *count < 2 {
+		return usageErrorf("usage: go-quiz elimination [-players n] [-listen address] [quiz flags] quiz.csv")
+	}
+	filePath, err := filepath.Abs(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error expanding path: %w", err)
+	}
+	settings, err := readQuizSettings(filePath)
+	if err != nil {
+		return err
+	}
+	if err := asParseError(filePath, settings.apply(flags)); err != nil {
+		return err
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	questions, err := duelQuestions(filePath, opts, settings.Constraints)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fmt.Errorf("error hosting game: %w", err)
+	}
+	writeWaiting(listener, fmt.Sprintf("%d players", *count))
+	players, err := acceptPlayers(ctx, listener, *count, "game")
+	listener.Close()
+	if err != nil {
+		return err
+	}
+
+	elapsed := make(map[*tournamentPlayer]time.Duration)
+	for i, q := range questions {
+		text := fmt.Sprintf("\nRound %d: %d players left.", i+1, len(players))
+		fmt.Fprintln(stdout, text[1:])
+		round := playRound(players, q, opts.ask.Timeout, text)
+		if ctx.Err() != nil {
+			// Interrupted: the answers were cut short, so no one is out.
+			slog.Info("elimination called off", "round", i+1)
+			for _, p := range players {
+				p.end("The game was called off by the host.")
+			}
+			return ctx.Err()
+		}
+
+		for _, a := range round {
+			if a.done == nil {
+				fmt.Fprintf(stdout, "  %s left the game.\n", a.player.name)
+				continue
+			}
+			elapsed[a.player] += a.done.Elapsed
+		}
+		left, out := eliminate(players, round)
+
+		var outcome string
+		switch {
+		case len(out) == 0 && len(left) < len(players):
+			outcome = fmt.Sprintf("Nobody else is out: %d left.", len(left))
+		case len(out) == 0:
+			outcome = "Everyone missed that one: nobody is out."
+		default:
+			var names []string
+			for _, p := range out {
+				names = append(names, p.name)
+			}
+			outcome = fmt.Sprintf("Out: %s. %d left.", strings.Join(names, ", "), len(left))
+		}
+		fmt.Fprintf(stdout, "  %s\n", outcome)
+		slog.Info("round played", "round", i+1, "outcome", outcome)
+		for _, a := range round {
+			a.player.d.send(duelMessage{Type: duelResult, Text: outcome})
+		}
+		for _, p := range out {
+			p.end("You are out, in round %d.", i+1)
+		}
+		players = left
+
+		if len(players) <= 1 {
+			break
+		}
+	}
+
+	if len(players) == 0 {
+		fmt.Fprintln(stdout, "\nEveryone left the game.")
+		return nil
+	}
+	winner := slices.MinFunc(players, func(a, b *tournamentPlayer) int {
+		return cmp.Compare(elapsed[a], elapsed[b])
+	})
+	if len(players) > 1 {
+		fmt.Fprintf(stdout, "\nThe questions ran out with %d players left: %s wins, having been the quickest.\n", len(players), winner.name)
+		for _, p := range players {
+			if p != winner {
+				p.end("The questions ran out: %s wins, having been the quickest of the %d left.", winner.name, len(players))
+			}
+		}
+	} else {
+		fmt.Fprintf(stdout, "\n%s wins the game!\n", winner.name)
+	}
+	slog.Info("elimination won", "winner", winner.name, "players_left", len(players))
+	winner.end("You win the game!")
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEliminate(t *testing.T) {
+	alice, bob, carol := &tournamentPlayer{name: "alice"}, &tournamentPlayer{name: "bob"}, &tournamentPlayer{name: "carol"}
+	players := []*tournamentPlayer{alice, bob, carol}
+	right := &duelMessage{Type: duelDone, Correct: 1, Points: 1}
+	wrong := &duelMessage{Type: duelDone}
+	names := func(players []*tournamentPlayer) []string {
+		var names []string
+		for _, p := range players {
+			names = append(names, p.name)
+		}
+		return names
+	}
+	tests := []struct {
+		name  string
+		round []roundAnswer
+		left  []string
+		out   []string
+	}{
+		{
+			name:  "missed the question",
+			round: []roundAnswer{{carol, right}, {bob, wrong}, {alice, right}},
+			left:  []string{"alice", "carol"},
+			out:   []string{"bob"},
+		},
+		{
+			name:  "everyone missed",
+			round: []roundAnswer{{carol, wrong}, {alice, wrong}, {bob, wrong}},
+			left:  []string{"alice", "bob", "carol"},
+		},
+		{
+			name:  "left the game",
+			round: []roundAnswer{{alice, wrong}, {bob, nil}, {carol, right}},
+			left:  []string{"carol"},
+			out:   []string{"alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, out := eliminate(players, tt.round)
+			if !slices.Equal(names(left), tt.left) || !slices.Equal(names(out), tt.out) {
+				t.Errorf("eliminate = %v left, %v out; want %v, %v", names(left), names(out), tt.left, tt.out)
+			}
+		})
+	}
+}
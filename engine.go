@@ -0,0 +1,450 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clock tells the time and waits for durations to pass.
+// The engine takes all its timings from a clock so runs can use a fake one.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the clock backed by the real time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// askOptions controls how each question is asked.
+type askOptions struct {
+	// Confirm requires the user to confirm each answer before it is submitted.
+	Confirm bool
+	// Timeout is the time allowed per question; 0 means no limit.
+	Timeout time.Duration
+	// TimeoutAnswer is submitted in place of an answer when the time runs out.
+	TimeoutAnswer string
+	// Proctor hides the answers from the screen, so they are only seen by the examiner.
+	Proctor bool
+	// Explain asks for an optional one-line reason after each answer.
+	Explain bool
+	// Commands lists the commands, separated by commas, that may be typed in
+	// place of an answer (see quizCommands); "none" allows none.
+	Commands string
+	// Cues signals timer milestones and the end of the time: "bell", "flash"
+	// or the path of a sound file. Empty gives no cues.
+	Cues string
+	// Pace is the delay between revealing each word (or line, see Reveal)
+	// of a question; 0 shows the whole question at once.
+	Pace time.Duration
+	// Reveal is the unit revealed at a time with Pace: "word" or "line".
+	Reveal string
+	// Large shows questions in double-size letters, on terminals that support it.
+	Large bool
+	// Spelling runs a spelling test: each question's answer is spoken, with
+	// its example sentence if any, and never shown.
+	Spelling bool
+	// WatchFocus notes every time the terminal loses focus during the quiz,
+	// on terminals that report it, as a sign of looking elsewhere in an exam.
+	WatchFocus bool
+	// SuddenDeath ends the quiz at the first answer not earning full credit;
+	// the questions left count as wrong.
+	SuddenDeath bool
+	// Streaks shows after each answer whether it was correct and the
+	// multiplier reached by the run of correct answers (see addStreaks).
+	Streaks bool
+	// HintTime is added to the time limit of a question the first time
+	// /hint is used on it.
+	HintTime time.Duration
+	// HintPenalty is the share of a question's credit lost by using /hint.
+	HintPenalty float64
+	// Revisions is the policy for revising answers after the last question:
+	// "free", "once" or "locked". Questions may set their own.
+	Revisions string
+}
+
+// engine asks questions and collects responses.
+//
+// It never touches the terminal directly: input, output and time are all
+// injected, so a quiz can be driven by a person, a script or another program.
+type engine struct {
+	// ctx is the context of the quiz being run, set by run: reading input
+	// gives up once it is done.
+	ctx    context.Context
+	in     lineSource
+	out    io.Writer
+	errOut io.Writer
+	clock  clock
+	// width and height are the size of the output in columns and lines.
+	// A width of 0 uses defaultTerminalWidth; a height of 0 disables paging.
+	width, height int
+	opts          askOptions
+	// sections holds the instructions and time limits of the quiz's sections, if any.
+	sections map[string]sectionSettings
+	// notes collects the notes taken with /note during the current question.
+	notes []string
+	// speak says text aloud for spelling tests; nil says nothing.
+	speak func(text string) error
+	// reports collects the questions reported with /report during the session.
+	reports []questionReport
+	// focusLost counts the times the terminal lost focus, with WatchFocus.
+	focusLost int
+	// quit is set once the user ends the quiz early with /quit, and discard
+	// if they then choose not to save the session.
+	quit, discard bool
+	// hinted is set once /hint has shown the hint of the current question.
+	hinted bool
+	// streak is the number of correct answers in a row so far, with Streaks.
+	streak int
+	// answered, if set, is called with each response as it is given, such
+	// as to show a duel's opponent the player's progress.
+	answered func(r response)
+	// grade, if set, grades each response as it is given or revised, for
+	// questions asked without their answers, such as a duel's joiner is
+	// sent (see joinDuel). It returns the share of a point the response
+	// earns.
+	grade func(r response) (float64, error)
+}
+
+// newTerminalEngine returns an engine reading stdin and writing to stdout,
+// sized to the terminal and using the system clock.
+func newTerminalEngine(opts askOptions) *engine {
+	_, height := ttySize(os.Stdout)
+	return &engine{
+		ctx:    context.Background(),
+		in:     stdin,
+		out:    stdout,
+		errOut: stderr,
+		clock:  systemClock{},
+		width:  terminalWidth(),
+		height: height,
+		opts:   opts,
+		speak:  speak,
+	}
+}
+
+// run asks each question in turn and returns the responses.
+//
+// A question whose answer cannot be read is reported and left out of the
+// responses, so it counts as unanswered. After the last question, answers
+// may be revised as the revision policies allow (see reviseAnswers).
+//
+// Typing /quit ends the quiz early, with the responses given so far (see
+// finishQuit); the questions left are not asked. With SuddenDeath, so does
+// the first answer to a graded question not earning full credit, and
+// answers cannot be revised.
+// Time spent giving a reason after the answer is not counted in its duration.
+//
+// The quiz also ends, without revisions, once ctx is done: reads waiting for
+// an answer give up and the questions left are not asked.
+func (e *engine) run(ctx context.Context, questions []question) []response {
+	e.ctx = ctx
+	// Pre-allocate to improve performance
+	responses := make([]response, 0, len(questions))
+	if e.opts.WatchFocus {
+		defer e.watchFocus()()
+	}
+
+	for i, q := range questions {
+		if (i == 0 && q.Stage != "") || (i > 0 && questions[i-1].Stage != q.Stage) {
+			e.introduceStage(q.Stage)
+		}
+		if q.Section != "" && (i == 0 || questions[i-1].Section != q.Section) {
+			e.introduceSection(q.Section)
+		}
+
+		start := e.clock.Now()
+		answer, err := e.ask(q)
+		if errors.Is(err, errQuit) {
+			e.notes, e.hinted = nil, false
+			e.finishQuit(len(responses), len(questions))
+			return responses
+		}
+		if err != nil && ctx.Err() != nil {
+			e.notes, e.hinted = nil, false
+			return responses
+		}
+		if err != nil {
+			e.notes, e.hinted = nil, false
+			fmt.Fprintf(e.errOut, "Error recording answer: %v\n", err)
+			continue
+		}
+		r := response{Question: q, Answer: answer, Duration: e.clock.Now().Sub(start), Notes: e.notes, HintPenalty: e.hintPenalty()}
+		e.notes, e.hinted = nil, false
+		if e.opts.Explain {
+			r.Explanation = e.explain()
+		}
+		if err := e.gradeResponse(&r); err != nil {
+			fmt.Fprintf(e.errOut, "Error grading answer: %v\n", err)
+			return responses
+		}
+		responses = append(responses, r)
+		if e.opts.Streaks && q.Stage == "" {
+			e.showStreak(r)
+		}
+		if e.answered != nil {
+			e.answered(r)
+		}
+		if e.opts.SuddenDeath && q.Stage == "" && !r.correct() {
+			e.endSuddenDeath(responses, questions)
+			return responses
+		}
+	}
+	if e.opts.SuddenDeath {
+		fmt.Fprintf(e.out, "\nSudden death: you survived all %d questions!\n", countGraded(questions))
+		return responses
+	}
+	return e.reviseAnswers(responses)
+}
+
+// gradeResponse sets the credit earned by r with the grade hook, if the
+// engine has one.
+func (e *engine) gradeResponse(r *response) error {
+	if e.grade == nil {
+		return nil
+	}
+	credit, err := e.grade(*r)
+	if err != nil {
+		return err
+	}
+	r.Graded = &credit
+	return nil
+}
+
+// runCommand carries out a command typed in place of an answer to q: /calc
+// evaluates an arithmetic expression, /note saves a note with the question's
+// response, /hint shows the question's hint (see showHint), /report flags the
+// question as wrong or ambiguous and /quit asks for confirmation to end the
+// quiz (see ask). Commands not allowed by the
+// Commands option are refused.
+func (e *engine) runCommand(q question, name, arg string) {
+	if !slices.Contains(strings.Split(e.opts.Commands, ","), name) {
+		fmt.Fprintf(e.out, "/%s is not allowed in this quiz.\n", name)
+		return
+	}
+
+	switch name {
+	case commandCalc:
+		value, err := calculate(arg)
+		if err != nil {
+			fmt.Fprintf(e.out, "Cannot calculate %q: %v\n", arg, err)
+			return
+		}
+		fmt.Fprintf(e.out, "= %s\n", formatCalc(value))
+	case commandNote:
+		if arg == "" {
+			fmt.Fprintln(e.out, "Usage: /note text")
+			return
+		}
+		e.notes = append(e.notes, arg)
+		fmt.Fprintln(e.out, "Note saved.")
+	case commandHint:
+		e.showHint(q)
+	case commandReport:
+		if arg == "" {
+			fmt.Fprintln(e.out, "Usage: /report what is wrong, e.g. /report bad answer key")
+			return
+		}
+		e.reports = append(e.reports, questionReport{Quiz: q.Quiz, Question: q.fileText(), Reason: arg, Time: e.clock.Now()})
+		fmt.Fprintln(e.out, "Thanks, the question has been reported. You can still answer it.")
+	case commandQuit:
+		e.quit = e.confirm("Quit the quiz? Your answers so far will be scored.")
+	}
+}
+
+// introduceSection announces the start of a section with its instructions, if any.
+func (e *engine) introduceSection(name string) {
+	fmt.Fprintf(e.out, "\n== %s ==\n", displayText(name))
+	if instructions := e.sections[name].Instructions; instructions != "" {
+		fmt.Fprintln(e.out, wrapText(displayText(instructions), e.columns(), ""))
+	}
+	fmt.Fprintln(e.out)
+}
+
+// timeout returns the time allowed for a question: its section's time limit
+// if it has one, and the -timeout option otherwise.
+func (e *engine) timeout(q question) time.Duration {
+	if t := e.sections[q.Section].Timeout; t > 0 {
+		return t
+	}
+	return e.opts.Timeout
+}
+
+// explain asks why the user gave their answer and returns the reason, which
+// may be blank. The reason is optional, so an error reading it is reported
+// and treated as no reason.
+func (e *engine) explain() string {
+	fmt.Fprint(e.out, "Why? (optional, Enter to skip) ")
+	reason, err := e.in.readLine(e.ctx, e.clock, 0)
+	if e.opts.Proctor {
+		// The reason is not echoed either, so end its line.
+		fmt.Fprintln(e.out)
+	}
+	if err != nil {
+		fmt.Fprintf(e.errOut, "Error recording reason: %v\n", err)
+		return ""
+	}
+	return strings.TrimSpace(reason)
+}
+
+// questionText returns the text of q as shown when it is asked: wrapped to
+// the screen, with any choices lettered and indented below it.
+func (e *engine) questionText(q question) string {
+	text := wrapText(displayQuestion(q.Text), e.wrapColumns(), "")
+	for i, choice := range q.Choices {
+		label := wrapText(fmt.Sprintf("%c) %s", 'a'+i, displayText(choice)), e.wrapColumns()-2, "   ")
+		text += "\n  " + strings.ReplaceAll(label, "\n", "\n  ")
+	}
+	return text
+}
+
+// ask shows a question and returns the user's answer.
+//
+// Multiple-choice questions list their choices under the question; a choice
+// picked by its letter is returned as the choice's text.
+//
+// Declining a confirmation asks for the answer again, within the same time limit.
+// So does typing a command such as "/calc 17*23" (see runCommand), except
+// a confirmed /quit, which returns errQuit.
+//
+// In a spelling test the question is spoken instead of shown, and spoken
+// again whenever a blank answer is entered.
+//
+// Returns:
+//   - string: the submitted answer, or the timeout answer if time ran out.
+//   - error: an error if reading the answer or the confirmation fails.
+func (e *engine) ask(q question) (string, error) {
+	text := e.questionText(q)
+	if e.opts.Spelling {
+		fmt.Fprintln(e.out, "Spell the word you hear (press Enter to hear it again):")
+		e.dictate(q)
+	} else if e.opts.Pace > 0 || e.opts.Large {
+		e.present(text)
+	} else {
+		e.show(text)
+	}
+
+	timeout := e.timeout(q)
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = e.clock.Now().Add(timeout)
+	}
+
+	for {
+		answer, err := e.readLine(deadline, timeout)
+		if errors.Is(err, errTimeout) {
+			playCue(e.out, e.opts.Cues)
+			fmt.Fprintln(e.out, "\nTime's up!")
+			return e.opts.TimeoutAnswer, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if name, arg, ok := parseCommand(answer); ok {
+			hinted := e.hinted
+			e.runCommand(q, name, arg)
+			if e.hinted && !hinted && !deadline.IsZero() && e.opts.HintTime > 0 {
+				deadline = deadline.Add(e.opts.HintTime)
+				fmt.Fprintf(e.out, "You have %s more for this question.\n", e.opts.HintTime)
+			}
+			if e.quit {
+				return "", errQuit
+			}
+			continue
+		}
+		if e.opts.Spelling && strings.TrimSpace(answer) == "" {
+			e.dictate(q)
+			continue
+		}
+		answer = q.choiceAnswer(answer)
+		if e.opts.Proctor {
+			fmt.Fprintln(e.out, hiddenAnswer)
+		}
+		if !e.opts.Confirm {
+			return answer, nil
+		}
+
+		if e.opts.Proctor {
+			fmt.Fprint(e.out, "Submit this answer? [y/N] ")
+		} else {
+			fmt.Fprintf(e.out, "You entered: %q. Submit? [y/N] ", answer)
+		}
+		reply, err := e.readLine(deadline, timeout)
+		if errors.Is(err, errTimeout) {
+			playCue(e.out, e.opts.Cues)
+			fmt.Fprintln(e.out, "\nTime's up!")
+			return e.opts.TimeoutAnswer, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if e.opts.Proctor {
+			// The reply is not echoed either, so end its line.
+			fmt.Fprintln(e.out)
+		}
+		if strings.EqualFold(strings.TrimSpace(reply), "y") {
+			return answer, nil
+		}
+		fmt.Fprintln(e.out, "Enter your answer again:")
+	}
+}
+
+// readLine reads the next line of input, giving up with errTimeout at the
+// deadline. A zero deadline waits indefinitely.
+//
+// With cues enabled, a cue is given at each milestone of the question's time
+// limit, timeout, passed while waiting. With WatchFocus, the terminal's focus
+// reports are taken out of the line and noted (see noteFocus).
+func (e *engine) readLine(deadline time.Time, timeout time.Duration) (string, error) {
+	line, err := e.readInput(deadline, timeout)
+	if e.opts.WatchFocus {
+		line = e.noteFocus(line)
+	}
+	return line, err
+}
+
+// readInput reads the next line of input for readLine.
+func (e *engine) readInput(deadline time.Time, timeout time.Duration) (string, error) {
+	if deadline.IsZero() {
+		return e.in.readLine(e.ctx, e.clock, 0)
+	}
+
+	for {
+		// An expired deadline still gets a minimal wait so reads time out at once.
+		remaining := max(deadline.Sub(e.clock.Now()), time.Nanosecond)
+
+		// Wait only until the next milestone, if there is one.
+		var milestone time.Duration
+		if e.opts.Cues != "" {
+			for _, m := range cueMilestones(timeout) {
+				if m < remaining {
+					milestone = m
+					break
+				}
+			}
+		}
+
+		line, err := e.in.readLine(e.ctx, e.clock, remaining-milestone)
+		if milestone > 0 && errors.Is(err, errTimeout) {
+			playCue(e.out, e.opts.Cues)
+			continue
+		}
+		return line, err
+	}
+}
+
+// columns returns the width to wrap text to.
+func (e *engine) columns() int {
+	if e.width > 0 {
+		return e.width
+	}
+	return defaultTerminalWidth
+}
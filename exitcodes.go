@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+)
+
+// Exit codes of go-quiz, so scripts can tell failures apart. They are listed
+// in the README and must not change meaning.
+const (
+	exitOK = 0
+	// exitError is any failure without a code of its own.
+	exitError = 1
+	// exitNotFound means a quiz or other input file does not exist.
+	exitNotFound = 2
+	// exitParseError means a quiz file or its settings are malformed.
+	exitParseError = 3
+	// exitFailed means the quiz was taken but the score is below the pass mark.
+	exitFailed = 4
+	// exitInterrupted means the quiz was ended early with /quit or Ctrl+C.
+	exitInterrupted = 5
+	// exitUsage means the command line is not valid: an unknown flag, a bad
+	// flag value, or missing or extra arguments.
+	exitUsage = 6
+)
+
+// errFailed is returned by commands that take a quiz when the score is below
+// the pass mark.
+var errFailed = errors.New("the score is below the pass mark")
+
+// errInterrupted is returned by commands that take a quiz when it is ended
+// early with /quit.
+var errInterrupted = errors.New("the quiz was ended early")
+
+// sessionError returns errInterrupted for a session ended with /quit,
+// errFailed for a score below the pass mark, and nil otherwise.
+func sessionError(e *engine, result summary) error {
+	switch {
+	case e.quit:
+		return errInterrupted
+	case result.failed():
+		return errFailed
+	}
+	return nil
+}
+
+// parseError is a quiz file, or its settings, that cannot be understood.
+type parseError struct {
+	// Path is the quiz file.
+	Path string
+	Err  error
+}
+
+func (e *parseError) Error() string { return e.Err.Error() }
+func (e *parseError) Unwrap() error { return e.Err }
+
+// asParseError wraps an error reading a quiz file as a parseError, unless it
+// is the file being missing or unreadable rather than malformed.
+func asParseError(filePath string, err error) error {
+	if err == nil || errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+		return err
+	}
+	return &parseError{Path: filePath, Err: err}
+}
+
+// usageError is a command line that is not valid for the command, such as
+// an unknown flag or a missing argument.
+type usageError struct {
+	msg string
+	// reported is set once the error has been shown, as the flag package
+	// shows its errors along with the usage.
+	reported bool
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+// usageErrorf returns a usageError with a message formatted as by
+// fmt.Sprintf, usually starting with "usage:" and the command's synopsis.
+func usageErrorf(format string, a ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, a...)}
+}
+
+// parseFlags parses the arguments of a command into its flags. A parse
+// error, which the flag package has already shown with the usage, is
+// returned as a reported usageError, and -help as flag.ErrHelp.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return &usageError{msg: err.Error(), reported: true}
+}
+
+// reported reports whether err has already been shown to the user, so it
+// should not be logged again: the errors of parseFlags, those of a session,
+// whose outcome is shown with the score, and those of work cancelled by
+// Ctrl+C (see handleInterrupts).
+func reported(err error) bool {
+	var usageErr *usageError
+	return errors.Is(err, flag.ErrHelp) || errors.Is(err, errFailed) || errors.Is(err, errInterrupted) ||
+		errors.Is(err, context.Canceled) || errors.As(err, &usageErr) && usageErr.reported
+}
+
+// exitCode returns the exit code for a failure.
+func exitCode(err error) int {
+	var parseErr *parseError
+	var usageErr *usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.Is(err, fs.ErrNotExist):
+		return exitNotFound
+	case errors.As(err, &parseErr):
+		return exitParseError
+	case errors.Is(err, errFailed):
+		return exitFailed
+	case errors.Is(err, errInterrupted), errors.Is(err, context.Canceled):
+		return exitInterrupted
+	}
+	return exitError
+}
+
+// interruptCleanups are run, last first, when go-quiz exits at a second Ctrl+C.
+var (
+	interruptMu       sync.Mutex
+	interruptCleanups []func()
+)
+
+// atInterrupt registers cleanup to run if go-quiz is made to exit at once by
+// a second Ctrl+C (see handleInterrupts), e.g. to restore the terminal. It is
+// run at most once, and should be harmless if the program has already cleaned
+// up on its own.
+func atInterrupt(cleanup func()) {
+	interruptMu.Lock()
+	defer interruptMu.Unlock()
+	interruptCleanups = append(interruptCleanups, cleanup)
+}
+
+// handleInterrupts makes Ctrl+C cancel the returned context. The command
+// running then stops as it would at the end, cleaning up on its way out, and
+// main exits with exitInterrupted. A second Ctrl+C, for a command slow to
+// stop, runs the cleanups registered with atInterrupt and exits at once.
+func handleInterrupts() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	go func() {
+		<-interrupted
+		cancel()
+		fmt.Fprintln(stderr, "\nInterrupted: stopping. Press Ctrl+C again to exit at once.")
+		<-interrupted
+		interruptMu.Lock()
+		for _, cleanup := range slices.Backward(interruptCleanups) {
+			cleanup()
+		}
+		fmt.Fprintln(stderr, "\nInterrupted.")
+		os.Exit(exitInterrupted)
+	}()
+	return ctx
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fetchTimeout bounds every request made to fetch questions.
+const fetchTimeout = 30 * time.Second
+
+// fetchSources maps the source named after "fetch" to the function fetching
+// questions from it, given the remaining arguments.
+var fetchSources = map[string]func(ctx context.Context, args []string) error{
+	"opentdb":  fetchOpenTDB,
+	"wikidata": fetchWikidata,
+}
+
+// runFetch implements the "fetch" subcommand.
+//
+// It pulls questions from an online source into a quiz file in the native
+// CSV format, for instant content that can then be edited like any quiz.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name, starting with the source.
+//
+// Returns:
+//   - error: an error if the source is unknown, or as for the source.
+func runFetch(ctx context.Context, args []string) error {
+	sources := slices.Sorted(maps.Keys(fetchSources))
+	if len(args) == 0 || fetchSources[args[0]] == nil {
+		return usageErrorf("usage: go-quiz fetch source [flags]: sources are %s", strings.Join(sources, ", "))
+	}
+	return fetchSources[args[0]](ctx, args[1:])
+}
+
+// writeFetchedQuiz writes fetched questions as a CSV quiz.
+//
+// Parameters:
+//   - path: the file to write; empty writes to standard output.
+//   - credit: a comment line naming the source, as its license may require.
+//   - headers: the header row.
+//   - rows: the questions, one row each.
+//
+// Returns:
+//   - error: an error if the file cannot be written.
+func writeFetchedQuiz(path, credit string, headers []string, rows [][]string) error {
+	var w io.Writer = os.Stdout
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("error creating output: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	fmt.Fprintf(w, "%c %s\n", commentChar, credit)
+	cw := csv.NewWriter(w)
+	cw.Write(headers)
+	cw.WriteAll(rows)
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error writing quiz: %w", err)
+	}
+	if path != "" {
+		notice(fmt.Sprintf("Wrote %d questions to %s", len(rows), path), "file", path)
+	}
+	return nil
+}
+
+// getJSON fetches a URL and decodes its JSON body into v, giving up if ctx is
+// done first. Requests name go-quiz as their user agent, as some APIs require.
+func getJSON(ctx context.Context, location string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return fmt.Errorf("error fetching %s: %w", location, err)
+	}
+	ver, _, _ := buildInfo()
+	req.Header.Set("User-Agent", "go-quiz/"+ver)
+	req.Header.Set("Accept", "application/json")
+
+	client := http.Client{Timeout: fetchTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error fetching %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error fetching %s: %s", location, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error parsing response from %s: %w", location, err)
+	}
+	return nil
+}
+
+// openTDBURL is the question API of the Open Trivia Database.
+const openTDBURL = "https://opentdb.com/api.php"
+
+// openTDBMaxCount is the most questions the Open Trivia Database returns at once.
+const openTDBMaxCount = 50
+
+// openTDBErrors explains the Open Trivia Database's response codes other than success.
+var openTDBErrors = map[int]string{
+	1: "not enough questions for the category, difficulty and type asked for",
+	2: "invalid category, difficulty or type",
+	5: "too many requests: wait a few seconds and try again",
+}
+
+// openTDBResponse is the JSON returned by the Open Trivia Database.
+type openTDBResponse struct {
+	ResponseCode int `json:"response_code"`
+	Results      []struct {
+		Category         string   `json:"category"`
+		Question         string   `json:"question"`
+		CorrectAnswer    string   `json:"correct_answer"`
+		IncorrectAnswers []string `json:"incorrect_answers"`
+	} `json:"results"`
+}
+
+// fetchOpenTDB implements "fetch opentdb".
+//
+// It fetches trivia questions from the Open Trivia Database, decoding their
+// HTML entities, as multiple-choice questions with the answer placed among
+// the wrong ones at random. True or false statements are asked as "True or
+// false: ..." with the choices True and False.
+//
+// Parameters:
+//   - ctx: the context; the request gives up when it is done.
+//   - args: the command-line arguments following the source name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, or the questions cannot
+//     be fetched or written.
+func fetchOpenTDB(ctx context.Context, args []string) error {
+	flags := newFlagSet("fetch opentdb")
+	category := flags.Int("category", 0, "Open Trivia Database category `number`, e.g. 18 for computers (0 for any)")
+	count := flags.Int("count", 10, fmt.Sprintf("number of questions to fetch, at most %d", openTDBMaxCount))
+	difficulty := flags.String("difficulty", "", "only fetch questions of this `difficulty`: easy, medium or hard")
+	kind := flags.String("type", "", "only fetch questions of this `type`: multiple (choice) or boolean (true or false)")
+	output := flags.String("o", "", "write the quiz to `file` instead of standard output")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 || *count < 1 || *count > openTDBMaxCount {
+		return usageErrorf("usage: go-quiz fetch opentdb [-category n] [-count 1-%d] [-difficulty level] [-type multiple|boolean] [-o file]", openTDBMaxCount)
+	}
+
+	query := url.Values{"amount": {strconv.Itoa(*count)}}
+	if *category != 0 {
+		query.Set("category", strconv.Itoa(*category))
+	}
+	if *difficulty != "" {
+		query.Set("difficulty", *difficulty)
+	}
+	if *kind != "" {
+		query.Set("type", *kind)
+	}
+
+	var resp openTDBResponse
+	if err := getJSON(ctx, openTDBURL+"?"+query.Encode(), &resp); err != nil {
+		return err
+	}
+	if resp.ResponseCode != 0 {
+		reason, ok := openTDBErrors[resp.ResponseCode]
+		if !ok {
+			reason = fmt.Sprintf("response code %d", resp.ResponseCode)
+		}
+		return fmt.Errorf("the Open Trivia Database returned no questions: %s", reason)
+	}
+
+	rows := make([][]string, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		answer := html.UnescapeString(r.CorrectAnswer)
+		choices := []string{answer}
+		for _, wrong := range r.IncorrectAnswers {
+			choices = append(choices, html.UnescapeString(wrong))
+		}
+		// Questions are asked with a question mark added.
+		text := strings.TrimSuffix(strings.TrimSpace(html.UnescapeString(r.Question)), "?")
+		if len(choices) == 2 && (answer == "True" || answer == "False") {
+			choices = []string{"True", "False"}
+			text = "True or false: " + strings.TrimSuffix(text, ".")
+		} else {
+			rand.Shuffle(len(choices), func(i, j int) { choices[i], choices[j] = choices[j], choices[i] })
+		}
+		rows = append(rows, []string{text, answer, html.UnescapeString(r.Category), strings.Join(choices, choiceSeparator)})
+	}
+
+	return writeFetchedQuiz(*output, "Questions from the Open Trivia Database, opentdb.com, licensed CC BY-SA 4.0",
+		[]string{"question", "answer", "category", "choices"}, rows)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Escape sequences of xterm focus reporting, supported by most terminal
+// emulators: the first two turn it on and off, and the terminal then sends
+// the last two as input when its window gains or loses focus.
+const (
+	focusReportingOn  = "\x1b[?1004h"
+	focusReportingOff = "\x1b[?1004l"
+	focusIn           = "\x1b[I"
+	focusOut          = "\x1b[O"
+)
+
+// watchFocus turns on focus reporting for the quiz and returns the function
+// that turns it off again, printing how many times focus was lost. It is
+// also turned off if the quiz is interrupted.
+func (e *engine) watchFocus() func() {
+	fmt.Fprint(e.out, focusReportingOn)
+	atInterrupt(func() { fmt.Fprint(os.Stdout, focusReportingOff) })
+	return func() {
+		fmt.Fprint(e.out, focusReportingOff)
+		switch {
+		case e.focusLost == 1:
+			fmt.Fprintln(e.out, "The quiz lost focus once.")
+		case e.focusLost > 1:
+			fmt.Fprintf(e.out, "The quiz lost focus %d times.\n", e.focusLost)
+		}
+	}
+}
+
+// noteFocus takes the focus reports out of a line of input. The terminal
+// only passes them on with the line, so a loss of focus is seen when the
+// answer is entered: it is then pointed out, and noted with the question's
+// response so it shows in the results.
+func (e *engine) noteFocus(line string) string {
+	lost := strings.Count(line, focusOut)
+	line = strings.NewReplacer(focusIn, "", focusOut, "").Replace(line)
+	if lost == 0 {
+		return line
+	}
+	e.focusLost += lost
+	note := "focus lost while answering"
+	if lost > 1 {
+		note = fmt.Sprintf("focus lost %d times while answering", lost)
+	}
+	e.notes = append(e.notes, note)
+	fmt.Fprintln(e.out, "Warning: the quiz window lost focus; this has been noted.")
+	return line
+}
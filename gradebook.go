@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultGradebookMapping is the column layout accepted by the Canvas and
+// Moodle grade importers. The score is in points, so partial credit counts.
+const defaultGradebookMapping = "Student ID=user,Score=points,Max Points=total"
+
+// gradebookColumn pairs an output column header with the history field it is filled from.
+type gradebookColumn struct {
+	Header string
+	Field  string
+}
+
+// gradebookFields lists the history fields that can be referenced in a mapping.
+var gradebookFields = map[string]func(e historyEntry) string{
+	"user":    func(e historyEntry) string { return e.User },
+	"correct": func(e historyEntry) string { return strconv.Itoa(e.Correct) },
+	"total":   func(e historyEntry) string { return strconv.Itoa(e.Total) },
+	"points":  gradebookPoints,
+	"score":   func(e historyEntry) string { return strconv.FormatFloat(e.Score, 'f', 1, 64) },
+	"time":    func(e historyEntry) string { return e.Time.Format(time.RFC3339) },
+}
+
+// gradebookPoints returns the recorded score of an attempt out of its total,
+// so that partial credit and hint penalties count, unlike correct.
+func gradebookPoints(e historyEntry) string {
+	return strconv.FormatFloat(math.Round(e.Score*float64(e.Total))/100, 'f', -1, 64)
+}
+
+// runExportGradebook implements the "export-gradebook" subcommand.
+//
+// It writes the recorded attempts of every user on a quiz, combined into one
+// grade per user, as a CSV file that can be imported into an LMS gradebook.
+// The attempts are combined as chosen with -aggregate or by the quiz's
+// aggregate setting, and by taking the latest attempt otherwise.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the export fails.
+func runExportGradebook(ctx context.Context, args []string) error {
+	flags := newFlagSet("export-gradebook")
+	mapping := flags.String("map", defaultGradebookMapping,
+		"comma-separated `header=field` pairs; fields are user, correct, total, points, score and time")
+	output := flags.String("o", "", "write the CSV to `file` instead of standard output")
+	aggregate := flags.String("aggregate", "latest", "grade each user by their `best`, latest or average attempt")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageErrorf("usage: go-quiz export-gradebook [-map mapping] [-aggregate how] [-o file] quiz.csv")
+	}
+
+	// The history outlives quiz files, so a quiz that is gone has no settings.
+	if settings, err := readQuizSettings(flags.Arg(0)); err == nil {
+		if err := settings.apply(flags); err != nil {
+			return err
+		}
+	}
+
+	columns, err := parseGradebookMapping(*mapping)
+	if err != nil {
+		return err
+	}
+
+	quiz, err := filepath.Abs(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error expanding path: %w", err)
+	}
+
+	entries, err := loadHistory(ctx, quiz)
+	if err != nil {
+		return err
+	}
+	grades, _, err := aggregatePerUser(entries, *aggregate)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("error creating output: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return writeGradebook(w, columns, grades)
+}
+
+// parseGradebookMapping parses a mapping such as "Student ID=user,Score=correct".
+//
+// Returns:
+//   - []gradebookColumn: the output columns in the order given.
+//   - error: an error if a pair is malformed or references an unknown field.
+func parseGradebookMapping(mapping string) ([]gradebookColumn, error) {
+	var columns []gradebookColumn
+	for _, pair := range strings.Split(mapping, ",") {
+		header, field, ok := strings.Cut(pair, "=")
+		header, field = strings.TrimSpace(header), strings.TrimSpace(field)
+		if !ok || header == "" {
+			return nil, fmt.Errorf("invalid mapping %q: expected header=field", pair)
+		}
+		if _, known := gradebookFields[field]; !known {
+			return nil, fmt.Errorf("invalid mapping %q: unknown field %q", pair, field)
+		}
+		columns = append(columns, gradebookColumn{Header: header, Field: field})
+	}
+	return columns, nil
+}
+
+// writeGradebook writes the header row followed by one row per entry.
+func writeGradebook(w io.Writer, columns []gradebookColumn, entries []historyEntry) error {
+	writer := csv.NewWriter(w)
+
+	headers := make([]string, 0, len(columns))
+	for _, c := range columns {
+		headers = append(headers, c.Header)
+	}
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf("error writing gradebook: %w", err)
+	}
+
+	for _, e := range entries {
+		row := make([]string, 0, len(columns))
+		for _, c := range columns {
+			row = append(row, gradebookFields[c.Field](e))
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("error writing gradebook: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing gradebook: %w", err)
+	}
+	return nil
+}
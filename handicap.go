@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handicap evens out a match between players of different skill.
+type handicap struct {
+	// Multiplier scales the player's points; 0 means 1.
+	Multiplier float64 `json:"multiplier,omitempty"`
+	// ExtraTime is added to the time allowed per question, and taken off
+	// the player's time for each answer when deciding a match on time.
+	ExtraTime time.Duration `json:"extra_time,omitempty"`
+}
+
+// points returns the points a player with the handicap earns for credit.
+func (h handicap) points(credit float64) float64 {
+	if h.Multiplier == 0 {
+		return credit
+	}
+	return credit * h.Multiplier
+}
+
+// elapsed returns a player's time for deciding a match, having answered
+// answered questions in actual time.
+func (h handicap) elapsed(actual time.Duration, answered int) time.Duration {
+	return max(actual-h.ExtraTime*time.Duration(answered), 0)
+}
+
+// String formats the handicap as given with -handicap, e.g. x1.5+10s.
+func (h handicap) String() string {
+	var s string
+	if h.Multiplier != 0 {
+		s = "x" + strconv.FormatFloat(h.Multiplier, 'g', -1, 64)
+	}
+	if h.ExtraTime != 0 {
+		s += "+" + h.ExtraTime.String()
+	}
+	return s
+}
+
+// parseHandicaps parses the value of -handicap: comma-separated entries of
+// name=handicap, the handicap being a point multiplier such as x1.5, extra
+// time per question such as +10s, or both, as in x1.5+10s.
+//
+// Parameters:
+//   - value: the flag's value; empty gives no handicaps.
+//
+// Returns:
+//   - map[string]handicap: the handicap of each player named.
+//   - error: an error naming the entry if one is malformed.
+func parseHandicaps(value string) (map[string]handicap, error) {
+	handicaps := make(map[string]handicap)
+	if value == "" {
+		return handicaps, nil
+	}
+	for _, entry := range strings.Split(value, ",") {
+		name, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" || spec == "" {
+			return nil, fmt.Errorf("invalid -handicap %q: use name=x1.5, name=+10s or name=x1.5+10s", entry)
+		}
+		var h handicap
+		multiplier, extra, hasExtra := strings.Cut(spec, "+")
+		if multiplier != "" {
+			m, err := strconv.ParseFloat(strings.TrimPrefix(multiplier, "x"), 64)
+			if !strings.HasPrefix(multiplier, "x") || err != nil || m <= 0 {
+				return nil, fmt.Errorf("invalid point multiplier %q for %s in -handicap: use a positive number after x, e.g. x1.5", multiplier, name)
+			}
+			h.Multiplier = m
+		}
+		if hasExtra {
+			d, err := time.ParseDuration(extra)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid extra time %q for %s in -handicap: use a duration, e.g. +10s", extra, name)
+			}
+			h.ExtraTime = d
+		}
+		handicaps[name] = h
+	}
+	return handicaps, nil
+}
+
+// warnUnknownHandicaps warns of handicaps given to players who are not
+// playing, as their names may be misspelt.
+func warnUnknownHandicaps(handicaps map[string]handicap, players []string) {
+	for _, name := range slices.Sorted(maps.Keys(handicaps)) {
+		if !slices.Contains(players, name) {
+			slog.Warn(fmt.Sprintf("-handicap names %s, who is not playing", name), "player", name)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import "fmt"
+
+// showHint shows the hint of the question being asked, for /hint. The first
+// time, it says what using the hint costs; the extra time, if any, is given
+// by ask.
+func (e *engine) showHint(q question) {
+	if q.Hint == "" {
+		fmt.Fprintln(e.out, "There is no hint for this question.")
+		return
+	}
+	fmt.Fprintln(e.out, wrapText("Hint: "+displayText(q.Hint), e.wrapColumns(), "      "))
+	if !e.hinted && e.opts.HintPenalty > 0 {
+		fmt.Fprintf(e.out, "Using the hint costs %g%% of the question's point.\n", e.opts.HintPenalty*100)
+	}
+	e.hinted = true
+}
+
+// hintPenalty returns the share of credit lost on the current question for
+// using /hint.
+func (e *engine) hintPenalty() float64 {
+	if !e.hinted {
+		return 0
+	}
+	return e.opts.HintPenalty
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+const historyFileName = "history.jsonl"
+
+// historyEntry is a single recorded quiz attempt.
+type historyEntry struct {
+	Quiz    string    `json:"quiz"`
+	User    string    `json:"user,omitempty"`
+	Time    time.Time `json:"time"`
+	Correct int       `json:"correct"`
+	Total   int       `json:"total"`
+	Score   float64   `json:"score"`
+	// Categories holds the per-category tallies when the quiz has categories.
+	Categories []categoryScore `json:"categories,omitempty"`
+	// Questions holds the outcome of each question answered, for calibrating difficulty.
+	Questions []questionOutcome `json:"questions,omitempty"`
+	// Flags names the flags set for the attempt, for export-usage.
+	Flags []string `json:"flags,omitempty"`
+	// Daily is the date of the daily quiz the attempt was on, if any.
+	Daily string `json:"daily,omitempty"`
+}
+
+// questionOutcome is how a single question went in a recorded attempt.
+type questionOutcome struct {
+	// Question is the question's text in the file, whichever way it was asked.
+	Question string `json:"question"`
+	// Reversed records that the question was asked the other way round (see -direction).
+	Reversed bool    `json:"reversed,omitempty"`
+	Correct  bool    `json:"correct"`
+	Seconds  float64 `json:"seconds"`
+}
+
+// newQuestionOutcomes converts responses into their history form.
+func newQuestionOutcomes(responses []response) []questionOutcome {
+	outcomes := make([]questionOutcome, 0, len(responses))
+	for _, r := range responses {
+		outcomes = append(outcomes, questionOutcome{
+			Question: r.Question.fileText(),
+			Reversed: r.Question.Reversed,
+			Correct:  r.correct(),
+			Seconds:  r.Duration.Seconds(),
+		})
+	}
+	return outcomes
+}
+
+// stateDir returns the directory where go-quiz keeps its local state.
+//
+// It honours $XDG_STATE_HOME and falls back to ~/.local/state/go-quiz.
+//
+// Returns:
+//   - string: the absolute path to the state directory (not necessarily existing yet).
+//   - error: an error if the home directory cannot be determined.
+func stateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "go-quiz"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error locating home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "go-quiz"), nil
+}
+
+// quizStatePath returns the path of a per-quiz state file, such as an index or
+// cache, keyed by a hash of the quiz file's absolute path.
+//
+// Parameters:
+//   - kind: the subdirectory of the state directory holding this kind of file.
+//   - filePath: the path of the quiz file the state belongs to.
+//   - ext: the extension of the state file, including the dot.
+func quizStatePath(kind, filePath, ext string) (string, error) {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("error expanding path: %w", err)
+	}
+
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, kind, hex.EncodeToString(sum[:8])+ext), nil
+}
+
+// historyPath returns the path of the history file inside the state directory.
+func historyPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, historyFileName), nil
+}
+
+// loadHistory reads all recorded attempts for the given quiz from the
+// storage in use (see openStorage).
+//
+// Parameters:
+//   - ctx: the context; a storage server is not waited for once it is done.
+//   - quiz: the absolute path of the quiz file the attempts belong to,
+//     or an empty string to load the attempts on every quiz.
+//
+// Returns:
+//   - []historyEntry: the attempts in the order they were recorded.
+//   - error: an error if the history exists but cannot be read or parsed.
+//
+// Note:
+//   - A missing history is not an error; it simply means there are no attempts yet.
+func loadHistory(ctx context.Context, quiz string) ([]historyEntry, error) {
+	store, err := openStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.loadHistory(ctx, quiz)
+}
+
+// appendHistory records an attempt in the storage in use.
+func appendHistory(ctx context.Context, entry historyEntry) error {
+	store, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	return store.appendHistory(ctx, entry)
+}
+
+// loadHistory reads the attempts on quiz, or on every quiz if it is empty,
+// from the history file. A missing file means there are none yet.
+func (fileStorage) loadHistory(_ context.Context, quiz string) ([]historyEntry, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening history: %w", err)
+	}
+	defer file.Close()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("error parsing history: %w", err)
+		}
+		if quiz == "" || entry.Quiz == quiz {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history: %w", err)
+	}
+
+	return entries, nil
+}
+
+// appendHistory records an attempt at the end of the history file,
+// creating the state directory and file if needed.
+func (fileStorage) appendHistory(_ context.Context, entry historyEntry) error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating state directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening history: %w", err)
+	}
+	defer file.Close()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding history: %w", err)
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing history: %w", err)
+	}
+	return nil
+}
+
+// recordAttempt prints how the attempt compares to the user's previous ones and adds it to the history.
+//
+// Parameters:
+//   - ctx: the context of the requests to a storage server, if one is used.
+//   - w: where to print the comparison.
+//   - filePath: the path of the quiz file that was taken.
+//   - attempt: the outcome of the attempt; the quiz, user and time are filled in here.
+//
+// Returns:
+//   - error: an error if the history cannot be read or written.
+func recordAttempt(ctx context.Context, w io.Writer, filePath string, attempt historyEntry) error {
+	quiz, err := filepath.Abs(filePath)
+	if err != nil {
+		return fmt.Errorf("error expanding path: %w", err)
+	}
+
+	user := currentUser()
+	entries, err := loadHistory(ctx, quiz)
+	switch {
+	case errors.Is(err, errUnreachable):
+		slog.Warn(fmt.Sprintf("cannot compare with previous attempts: %v", err))
+	case err != nil:
+		return err
+	default:
+		// Only the user's own attempts count, as the history may be shared.
+		var past []historyEntry
+		for _, e := range entries {
+			if e.User == user {
+				past = append(past, e)
+			}
+		}
+		fmt.Fprintln(w, compareAttempt(past, attempt.Score))
+	}
+
+	attempt.Quiz = quiz
+	attempt.User = user
+	attempt.Time = time.Now()
+	return appendHistory(ctx, attempt)
+}
+
+// compareAttempt describes how a score ranks against previous attempts.
+//
+// Parameters:
+//   - past: the user's previously recorded attempts on the same quiz.
+//   - score: the percentage score of the current attempt.
+//
+// Returns:
+//   - string: a short human-readable comparison, e.g. "Best score yet!" or
+//     "Better than 80% of your previous attempts."
+func compareAttempt(past []historyEntry, score float64) string {
+	if len(past) == 0 {
+		return "First attempt at this quiz."
+	}
+
+	beaten := 0
+	best := 0.0
+	for _, p := range past {
+		if score > p.Score {
+			beaten++
+		}
+		best = max(best, p.Score)
+	}
+
+	switch {
+	case score > best:
+		return fmt.Sprintf("Best score yet! (%d previous attempts)", len(past))
+	case score == best:
+		return fmt.Sprintf("Matched your best score. (%d previous attempts)", len(past))
+	}
+
+	percentile := float64(beaten) / float64(len(past)) * 100
+	return fmt.Sprintf("Better than %.0f%% of your %d previous attempts.", percentile, len(past))
+}
+
+// currentUser returns the name of the user taking the quiz.
+// It prefers the operating system account and falls back to $USER.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+)
+
+// indexHeaderSize is the size of the index file header: the source file's size and
+// modification time, used to detect a stale index.
+const indexHeaderSize = 16
+
+// readIndexedCSV reads a selection of records from a CSV file without loading the whole file.
+//
+// On first use an index of record offsets is built and stored in the state
+// directory; later runs reuse it until the CSV file changes. Only the header
+// row and the selected records are then parsed.
+//
+// Parameters:
+//   - filePath: the path to the CSV file.
+//   - limit: the number of records to read; 0 or less reads every record.
+//   - shuffle: whether to pick records at random instead of from the start of the file.
+//   - strict: whether a malformed row is an error instead of being left out of the index.
+//
+// Returns:
+//   - [][]string: the selected records, excluding the header row.
+//   - []string: the header row.
+//   - []rowError: the malformed rows skipped while building the index; empty when
+//     an existing index was reused.
+//   - error: an error if the file or its index cannot be read.
+func readIndexedCSV(filePath string, limit int, shuffle, strict bool) ([][]string, []string, []rowError, error) {
+	source, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer source.Close()
+
+	info, err := source.Stat()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading file info: %w", err)
+	}
+
+	headers, err := readHeader(csv.NewReader(source))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading headers: %w", err)
+	}
+
+	index, skipped, err := openIndex(filePath, info, strict)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer index.Close()
+
+	indexInfo, err := index.Stat()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading index: %w", err)
+	}
+	count := int((indexInfo.Size() - indexHeaderSize) / 8)
+
+	if limit <= 0 || limit > count {
+		limit = count
+	}
+
+	var positions []int
+	if shuffle {
+		positions = samplePositions(count, limit)
+	} else {
+		positions = make([]int, limit)
+		for i := range positions {
+			positions[i] = i
+		}
+	}
+
+	records := make([][]string, 0, len(positions))
+	buf := make([]byte, 8)
+	for _, pos := range positions {
+		if _, err := index.ReadAt(buf, indexHeaderSize+int64(pos)*8); err != nil {
+			return nil, nil, nil, fmt.Errorf("error reading index: %w", err)
+		}
+		offset := int64(binary.LittleEndian.Uint64(buf))
+
+		reader := csv.NewReader(io.NewSectionReader(source, offset, info.Size()-offset))
+		record, err := reader.Read()
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("error reading record %d: %w", pos+1, err)
+		}
+		if len(record) != len(headers) {
+			return nil, nil, nil, fmt.Errorf("error reading record %d: expected %d fields, got %d", pos+1, len(headers), len(record))
+		}
+		records = append(records, record)
+	}
+
+	return records, headers, skipped, nil
+}
+
+// samplePositions picks k distinct positions out of n in random order.
+//
+// It uses Floyd's algorithm, so memory use depends on k rather than n.
+func samplePositions(n, k int) []int {
+	chosen := make(map[int]bool, k)
+	positions := make([]int, 0, k)
+	for j := n - k; j < n; j++ {
+		pos := rand.IntN(j + 1)
+		if chosen[pos] {
+			pos = j
+		}
+		chosen[pos] = true
+		positions = append(positions, pos)
+	}
+	rand.Shuffle(len(positions), func(i, j int) {
+		positions[i], positions[j] = positions[j], positions[i]
+	})
+	return positions
+}
+
+// openIndex opens the offset index for a CSV file, building it first if it
+// is missing or the CSV file has changed since it was built.
+//
+// The index stores the CSV file's size and modification time followed by the
+// byte offset of every well-formed record after the header as little-endian
+// uint64 values. It also returns the rows skipped if the index was built.
+func openIndex(filePath string, info fs.FileInfo, strict bool) (*os.File, []rowError, error) {
+	path, err := quizStatePath("index", filePath, ".idx")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	index, err := os.Open(path)
+	if err == nil {
+		header := make([]byte, indexHeaderSize)
+		if _, err := io.ReadFull(index, header); err == nil &&
+			int64(binary.LittleEndian.Uint64(header)) == info.Size() &&
+			int64(binary.LittleEndian.Uint64(header[8:])) == info.ModTime().UnixNano() {
+			return index, nil, nil
+		}
+		index.Close()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("error opening index: %w", err)
+	}
+
+	skipped, err := buildIndex(filePath, info, path, strict)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	index, err = os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening index: %w", err)
+	}
+	return index, skipped, nil
+}
+
+// buildIndex scans a CSV file once and writes the offset of every record to path.
+// The index is written to a temporary file first and renamed into place.
+// Malformed rows are left out of the index and returned, or abort the build in strict mode.
+func buildIndex(filePath string, info fs.FileInfo, path string, strict bool) ([]rowError, error) {
+	source, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer source.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("error creating index directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("error creating index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w := bufio.NewWriter(tmp)
+	buf := make([]byte, 8)
+
+	binary.LittleEndian.PutUint64(buf, uint64(info.Size()))
+	w.Write(buf)
+	binary.LittleEndian.PutUint64(buf, uint64(info.ModTime().UnixNano()))
+	w.Write(buf)
+
+	reader := csv.NewReader(bufio.NewReader(source))
+	reader.ReuseRecord = true
+	if _, err := readHeader(reader); err != nil {
+		return nil, fmt.Errorf("error reading headers: %w", err)
+	}
+
+	skipped, err := readRows(reader, source, strict, func(offset int64, _ []string) {
+		binary.LittleEndian.PutUint64(buf, uint64(offset))
+		w.Write(buf)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error indexing records: %w", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, fmt.Errorf("error writing index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("error writing index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("error saving index: %w", err)
+	}
+	return skipped, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// errTimeout is returned by readLine when no line arrives before the timeout.
+var errTimeout = errors.New("time is up")
+
+// lineSource supplies lines of input to the engine.
+type lineSource interface {
+	// readLine returns the next line, or errTimeout if none arrives within
+	// timeout as measured by c, or ctx's error if ctx is done first. A
+	// timeout of 0 or less waits indefinitely.
+	readLine(ctx context.Context, c clock, timeout time.Duration) (string, error)
+}
+
+// stdin reads standard input for every interactive prompt.
+var stdin lineSource = newLineReader(os.Stdin)
+
+// stdout and stderr receive the output of an interactive quiz.
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// lineReader reads lines from an input source in the background.
+//
+// All input goes through a single reader so that no line is lost in the
+// buffer of a reader that is no longer used, and so reads can time out.
+type lineReader struct {
+	src   io.Reader
+	once  sync.Once
+	lines chan string
+	// err is set before lines is closed and read only after.
+	err error
+}
+
+// newLineReader returns a lineReader for src. Reading starts on the first readLine call.
+func newLineReader(src io.Reader) *lineReader {
+	return &lineReader{src: src}
+}
+
+// start launches the goroutine that reads the source line by line.
+func (lr *lineReader) start() {
+	lr.lines = make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(lr.src)
+		for scanner.Scan() {
+			lr.lines <- scanner.Text()
+		}
+		if err := scanner.Err(); err != nil {
+			lr.err = fmt.Errorf("error reading input: %w", err)
+		} else {
+			// If input stream ends without providing any data (i.e. Ctrl+D)
+			lr.err = fmt.Errorf("no input provided")
+		}
+		close(lr.lines)
+	}()
+}
+
+// readLine returns the next line of input.
+//
+// Parameters:
+//   - ctx: the context; reading gives up when it is done.
+//   - c: the clock that measures the timeout.
+//   - timeout: how long to wait for the line; 0 or less waits indefinitely.
+//
+// Returns:
+//   - string: the line, without its line ending.
+//   - error: errTimeout if the timeout expires first, ctx's error if it is
+//     done first, or an error if input ends or cannot be read.
+func (lr *lineReader) readLine(ctx context.Context, c clock, timeout time.Duration) (string, error) {
+	lr.once.Do(lr.start)
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = c.After(timeout)
+	}
+
+	select {
+	case line, ok := <-lr.lines:
+		if !ok {
+			return "", lr.err
+		}
+		return line, nil
+	case <-expired:
+		return "", errTimeout
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
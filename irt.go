@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// raschIterations caps the Newton steps taken to estimate an ability.
+const raschIterations = 50
+
+// abilityEstimate is a test taker's ability on the logit scale of the Rasch
+// (one-parameter logistic) model: 0 is the ability that answers a question of
+// average difficulty correctly half of the time, and each unit up multiplies
+// the odds of a correct answer by e.
+type abilityEstimate struct {
+	Theta float64
+	// StdErr is the standard error of Theta.
+	StdErr float64
+	// Questions is the number of calibrated questions the estimate is based on.
+	Questions int
+}
+
+// raschDifficulty converts a calibrated difficulty, the share of wrong
+// answers, into a Rasch difficulty on the logit scale. Shares of 0 and 1 are
+// clamped, as they would place the question infinitely far away.
+func raschDifficulty(share float64) float64 {
+	share = min(max(share, 0.01), 0.99)
+	return math.Log(share / (1 - share))
+}
+
+// estimateAbility estimates ability from the responses to calibrated questions
+// under the Rasch model. Questions without a calibrated difficulty are left out.
+//
+// The estimate is the maximum a posteriori value with a standard normal prior,
+// which keeps it finite when every answer is right or every answer is wrong.
+//
+// Parameters:
+//   - responses: the answered questions.
+//
+// Returns:
+//   - *abilityEstimate: the estimate, or nil if no question was calibrated.
+func estimateAbility(responses []response) *abilityEstimate {
+	var difficulties []float64
+	var correct []bool
+	for _, r := range responses {
+		if r.Question.Calibrated {
+			difficulties = append(difficulties, raschDifficulty(r.Question.Difficulty))
+			correct = append(correct, r.correct())
+		}
+	}
+	if len(difficulties) == 0 {
+		return nil
+	}
+
+	theta, information := 0.0, 1.0
+	for range raschIterations {
+		// The gradient and information of the log posterior at theta.
+		gradient := -theta
+		information = 1.0
+		for i, b := range difficulties {
+			p := 1 / (1 + math.Exp(b-theta))
+			if correct[i] {
+				gradient += 1 - p
+			} else {
+				gradient -= p
+			}
+			information += p * (1 - p)
+		}
+
+		step := gradient / information
+		theta += step
+		if math.Abs(step) < 1e-6 {
+			break
+		}
+	}
+
+	return &abilityEstimate{Theta: theta, StdErr: 1 / math.Sqrt(information), Questions: len(difficulties)}
+}
+
+// String renders the estimate as a summary line fragment, e.g. "0.84 ± 0.52 logits (Rasch, 12 calibrated questions)".
+func (a abilityEstimate) String() string {
+	noun := "questions"
+	if a.Questions == 1 {
+		noun = "question"
+	}
+	return fmt.Sprintf("%.2f ± %.2f logits (Rasch, %d calibrated %s)", a.Theta, a.StdErr, a.Questions, noun)
+}
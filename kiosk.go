@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\x1b[H\x1b[2J"
+
+// showKiosk shows the questions one at a time on a cleared screen, each for
+// questionTime, then with its answer for answerTime. It stops early, with
+// ctx's error, once ctx is done.
+func (e *engine) showKiosk(ctx context.Context, questions []question, questionTime, answerTime time.Duration) error {
+	for i, q := range questions {
+		fmt.Fprint(e.out, clearScreen)
+		fmt.Fprintf(e.out, "Question %d of %d\n\n", i+1, len(questions))
+		if e.opts.Large {
+			e.present(e.questionText(q))
+		} else {
+			fmt.Fprintln(e.out, e.questionText(q))
+		}
+		if err := e.pause(ctx, questionTime); err != nil {
+			return err
+		}
+
+		answer := wrapText("Answer: "+displayText(answerKey(q)), e.wrapColumns(), "        ")
+		fmt.Fprintln(e.out)
+		if e.opts.Large {
+			e.present(answer)
+		} else {
+			fmt.Fprintln(e.out, answer)
+		}
+		if err := e.pause(ctx, answerTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pause waits for d to pass, or returns ctx's error if ctx is done first.
+func (e *engine) pause(ctx context.Context, d time.Duration) error {
+	select {
+	case <-e.clock.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runKiosk implements the "kiosk" subcommand.
+//
+// It shows a quiz on a screen nobody types at, such as in a lobby or at the
+// front of a classroom: each question is shown, then its answer, then the
+// next question, over and over until interrupted. The quiz file is read
+// again for each pass, so edits show up without restarting.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the quiz cannot be
+//     loaded.
+func runKiosk(ctx context.Context, args []string) error {
+	flags := newFlagSet("kiosk")
+	questionTime := flags.Duration("question-time", 10*time.Second, "show each question for `duration` before its answer")
+	answerTime := flags.Duration("answer-time", 5*time.Second, "show each answer for `duration` before the next question")
+	loops := flags.Int("loops", 0, "go through the quiz `n` times (0 loops until interrupted)")
+	lang := flags.String("lang", "", "use the question_<lang> and answer_<lang> columns")
+	shuffle := flags.Bool("shuffle", false, "put the questions in a new random order on each pass")
+	limit := flags.Int("limit", 0, "show at most `n` questions per pass (0 shows all)")
+	large := flags.Bool("large", false, "show questions and answers in double-size letters")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *questionTime <= 0 || *answerTime <= 0 || *loops < 0 {
+		return usageErrorf("usage: go-quiz kiosk [-question-time duration] [-answer-time duration] [-loops n] [-lang language] [-shuffle] [-limit n] [-large] quiz.csv")
+	}
+	filePath := flags.Arg(0)
+
+	eng := newTerminalEngine(askOptions{Large: *large})
+	for pass := 0; *loops == 0 || pass < *loops; pass++ {
+		settings, err := readQuizSettings(filePath)
+		if err != nil {
+			return err
+		}
+		questions, _, err := loadQuiz(filePath, loadOptions{Lang: *lang}, slog.Warn)
+		if err != nil {
+			return err
+		}
+		questions = selectQuestions(questions, *shuffle, settings.Constraints, *limit)
+		if len(questions) == 0 {
+			return fmt.Errorf("no questions to show in %s", filePath)
+		}
+		if err := eng.showKiosk(ctx, questions, *questionTime, *answerTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
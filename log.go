@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Formats of the log file written with -log-file, chosen with -log-format.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// logTarget is where structured logs go besides the terminal, as set by
+// -log-file and -log-format.
+var logTarget = struct {
+	file   io.Writer
+	format string
+}{format: logFormatText}
+
+// levelNotice is the level of notices: news for the user, such as the file
+// a command wrote, which unlike other info records is shown on the terminal.
+const levelNotice = slog.LevelInfo + 2
+
+// notice logs a notice (see levelNotice), with attributes as for slog.Info.
+func notice(msg string, args ...any) {
+	slog.Log(context.Background(), levelNotice, msg, args...)
+}
+
+// consoleHandler shows notices, warnings and errors on stderr as the rest
+// of go-quiz prints them: the message, after "Warning: " or "Error: " for
+// warnings and errors. Attributes are left out; they only go to the log
+// file.
+type consoleHandler struct{}
+
+func (consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= levelNotice
+}
+
+func (consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var prefix string
+	switch {
+	case r.Level >= slog.LevelError:
+		prefix = "Error: "
+	case r.Level >= slog.LevelWarn:
+		prefix = "Warning: "
+	}
+	_, err := fmt.Fprintf(stderr, "%s%s\n", prefix, r.Message)
+	return err
+}
+
+func (h consoleHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h consoleHandler) WithGroup(string) slog.Handler { return h }
+
+// teeHandler passes each record to every handler that is enabled for it.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var first error
+	for _, h := range t {
+		if h.Enabled(ctx, r.Level) {
+			if err := h.Handle(ctx, r.Clone()); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
+
+// configureLogging sets the default logger: notices, warnings and errors on
+// the terminal and, with -log-file, every record from info up in the file.
+func configureLogging() {
+	if logTarget.file == nil {
+		slog.SetDefault(slog.New(consoleHandler{}))
+		return
+	}
+	// Notices are named as such, rather than as INFO+2.
+	opts := &slog.HandlerOptions{ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.LevelKey && a.Value.Any() == levelNotice {
+			a.Value = slog.StringValue("NOTICE")
+		}
+		return a
+	}}
+	var file slog.Handler
+	if logTarget.format == logFormatJSON {
+		file = slog.NewJSONHandler(logTarget.file, opts)
+	} else {
+		file = slog.NewTextHandler(logTarget.file, opts)
+	}
+	slog.SetDefault(slog.New(teeHandler{consoleHandler{}, file}))
+}
+
+// registerLogFlags defines -log-file and -log-format on flags. The logger
+// is set up as they are parsed, so the log file is in use from then on; it
+// is appended to and left open until the program exits.
+func registerLogFlags(flags *flag.FlagSet) {
+	flags.Func("log-file", "also write warnings, errors and events as structured logs to `file`", func(path string) error {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("error opening log file: %w", err)
+		}
+		logTarget.file = file
+		configureLogging()
+		return nil
+	})
+	flags.Func("log-format", "`format` of the -log-file logs: text or json (default text)", func(format string) error {
+		if format != logFormatText && format != logFormatJSON {
+			return fmt.Errorf("use %s or %s", logFormatText, logFormatJSON)
+		}
+		logTarget.format = format
+		configureLogging()
+		return nil
+	})
+}
@@ -1,94 +1,269 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"encoding/csv"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 )
 
 const defaultFilePath = "./data/problems.csv"
 
+// subcommands maps the first command-line argument to the function handling it.
+// Running without a known subcommand starts an interactive quiz.
+var subcommands = map[string]func(ctx context.Context, args []string) error{
+	"export-gradebook": runExportGradebook,
+	"export-anki":      runExportAnki,
+	"export-usage":     runExportUsage,
+	"make-mcq":         runMakeMCQ,
+	"stats":            runStats,
+	"calibrate":        runCalibrate,
+	"mastery":          runMastery,
+	"recommend":        runRecommend,
+	"diff":             runDiff,
+	"bank-stats":       runBankStats,
+	"worksheet":        runWorksheet,
+	"cards":            runCards,
+	"mix":              runMix,
+	"course":           runCourse,
+	"verify-cert":      runVerifyCert,
+	"reports":          runReports,
+	"fetch":            runFetch,
+	"daily":            runDaily,
+	"duel":             runDuel,
+	"tournament":       runTournament,
+	"elimination":      runElimination,
+	"kiosk":            runKiosk,
+	"bench":            runBench,
+	"simulate":         runSimulate,
+	"clip":             runClip,
+	"version":          runVersion,
+	"browse":           runBrowse,
+	"get":              runGet,
+	"publish":          runPublish,
+	"backup":           runBackup,
+	"restore":          runRestore,
+	"storage-server":   runStorageServer,
+	"prune":            runPrune,
+	"user":             runUser,
+}
+
+// probedFlagSets collects the flag sets made by newFlagSet while
+// runCompletion probes a subcommand for its flags; it is nil otherwise.
+var probedFlagSets *[]*flag.FlagSet
+
+// newFlagSet makes the flag set of a subcommand, to be parsed with
+// parseFlags, with the logging flags already defined (see registerLogFlags).
+// Every subcommand makes its flags with it, so that the completion scripts
+// can list them: while probing, the flag set is recorded, prints nothing,
+// and panics on the parse error runCompletion provokes.
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	if probedFlagSets != nil {
+		flags.Init(name, flag.PanicOnError)
+		flags.SetOutput(io.Discard)
+		*probedFlagSets = append(*probedFlagSets, flags)
+	}
+	registerLogFlags(flags)
+	return flags
+}
+
 // main is the entry point of the program.
-// It orchestrates the flow of a quiz application that reads questions from a
-// CSV file, prompts the user for answers, and calculates the score.
+// It runs the subcommand named by the first argument, or else takes a quiz
+// (see runQuiz), and exits with the code for the outcome (see exitCode).
+func main() {
+	configureLogging()
+	ctx := handleInterrupts()
+	run, args := runQuiz, os.Args[1:]
+	var attrs []any
+	if len(os.Args) > 1 {
+		if command, ok := subcommands[os.Args[1]]; ok {
+			run, args = command, os.Args[2:]
+			attrs = []any{"command", os.Args[1]}
+		}
+	}
+	err := run(ctx, args)
+	if err == nil && ctx.Err() != nil {
+		// The command finished all the same, but not as asked.
+		err = errInterrupted
+	}
+	if err != nil && !reported(err) {
+		slog.Error(err.Error(), attrs...)
+	}
+	os.Exit(exitCode(err))
+}
+
+// runQuiz takes a quiz read from a CSV file, prompting the user for answers
+// and calculating the score.
 //
 // The function performs the following steps:
 // 1. Gets the file path for the CSV file containing quiz questions.
-// 2. Reads the CSV file, extracting headers and records.
-// 3. Iterates through the records, prompting the user for answers to each question.
-// 4. Calculates and displays the user's score.
+// 2. Applies the quiz's own settings, then reads the CSV file, extracting headers and records.
+// 3. Leaves out or weights questions as the flags say.
+// 4. Runs the session (see runSession), prompting for the answers, then scoring and recording them.
 //
 // Note:
 //   - The program assumes the CSV file is formatted with questions in the first column
 //     and correct answers in the second column.
+//   - Translations live in "question_<lang>" and "answer_<lang>" columns, selected with -lang.
+//   - An optional "category" column enables a per-category score breakdown.
+//   - An optional "romanization" column accepts a Latin-script spelling of the answer.
 //   - The score is calculated as a percentage of correct answers out of total questions.
-func main() {
-	filePath, err := getFilePath()
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments, the quiz flags.
+//
+// Returns:
+//   - error: an error if the quiz cannot be taken, or as for runSession.
+func runQuiz(ctx context.Context, args []string) error {
+	flag.CommandLine.Init(flag.CommandLine.Name(), flag.ContinueOnError)
+	opts := registerQuizFlags(flag.CommandLine)
+	registerLogFlags(flag.CommandLine)
+	if err := parseFlags(flag.CommandLine, args); err != nil {
+		return err
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	// The prompt for the quiz file is read from -answers-from and recorded.
+	stopRecording, err := opts.startInput()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		return err
 	}
+	defer stopRecording()
 
-	fmt.Println("Using filepath:", filePath)
+	filePath, err := getFilePath(ctx)
+	if err != nil {
+		return err
+	}
 
-	records, _, err := readCSV(filePath)
+	settings, err := readQuizSettings(filePath)
+	if err == nil {
+		err = asParseError(filePath, settings.apply(flag.CommandLine))
+	}
+	if err == nil {
+		err = opts.validate()
+	}
+	if err == nil && opts.ask.Spelling {
+		err = checkSpeech()
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading CSV: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 
-	fmt.Printf("Number of records: %d\n", len(records))
+	fmt.Fprintln(stdout, "Using filepath:", filePath)
+	if settings.Title != "" {
+		fmt.Fprintln(stdout, "Quiz:", settings.Title)
+	}
 
-	// Pre-allocate to improve performance
-	userAnswers := make([]string, 0, len(records))
-	correctAnswers := make([]string, 0, len(records))
+	questions, headers, err := loadQuiz(filePath, opts.load, slog.Warn)
+	if err != nil {
+		return fmt.Errorf("reading quiz file: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "Number of records: %d\n", len(questions))
 
-	for _, row := range records {
-		fmt.Printf("%s?\n", row[0])
-		answer, err := recordAnswer()
+	constraints := settings.Constraints
+	if opts.load.Indexed {
+		// Indexed mode samples the questions, so their numbers in the file are not known.
+		constraints = shuffleConstraints{}
+	}
+	var warmUp, coolDown []question
+	if !opts.load.Indexed {
+		warmUp, questions, coolDown = splitStages(questions, settings.WarmUp, settings.CoolDown)
+	}
+	questions = applyDirection(questions, opts.direction)
+	if opts.mastery > 0 {
+		var mastered int
+		questions, mastered, err = dropMastered(ctx, filePath, questions, opts.mastery)
+		if err == nil && len(questions) == 0 {
+			err = fmt.Errorf("you have mastered every question: reset with go-quiz mastery -reset, or use -mastery 0")
+		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error recording answer: %v\n", err)
-			continue
+			return err
+		}
+		switch {
+		case mastered == 1:
+			fmt.Fprintln(stdout, "Leaving out 1 mastered question.")
+		case mastered > 1:
+			fmt.Fprintf(stdout, "Leaving out %d mastered questions.\n", mastered)
 		}
-		userAnswers = append(userAnswers, answer)
-		correctAnswers = append(correctAnswers, row[1])
 	}
+	if opts.missDecay > 0 {
+		if questions, err = weightByMisses(ctx, filePath, questions, opts.missDecay); err != nil {
+			return err
+		}
+	}
+	// Weighting by misses already puts the questions in a random order.
+	questions = selectQuestions(questions, opts.load.Shuffle && opts.missDecay == 0, constraints, opts.load.Limit)
 
-	userPoints := calculateScore(userAnswers, correctAnswers)
-	userScore := float64(userPoints) / float64(len(records)) * 100
-
-	fmt.Printf("You got %d (%.1f%%) correct!\n", userPoints, userScore)
-
-	os.Exit(0)
+	return runSession(ctx, opts, flag.CommandLine, session{
+		quiz:       filePath,
+		questions:  slices.Concat(warmUp, questions, coolDown),
+		sections:   settings.Sections,
+		byCategory: columnIndex(headers, "category") >= 0,
+		attempt:    &historyEntry{},
+		title:      quizTitle(filePath, settings),
+	})
 }
 
 // getFilePath prompts the user for a file path and returns the validated, absolute path.
 //
-// The function uses a global variable 'defaultFilePath' which should be defined elsewhere.
+// Before prompting, it lists the recently used quizzes and those found in
+// the standard quiz directories (see quizSearchDirs) with their question
+// counts and latest scores, so one can be picked by number instead of typing
+// its path.
+//
+// The default, used when the user just presses Enter, is 'defaultFilePath'
+// if it exists and otherwise the first quiz listed.
+//
+// Parameters:
+//   - ctx: the context; the prompt gives up when it is done.
 //
 // Returns:
 //   - string: The validated file path. This will be the absolute path to the file.
 //   - error: An error if any step of the process fails.
-func getFilePath() (string, error) {
-	fmt.Printf("Enter file path [%s]: ", defaultFilePath)
+func getFilePath(ctx context.Context) (string, error) {
+	choices, recent := quizChoices(ctx)
 
-	scanner := bufio.NewScanner(os.Stdin)
-	if !scanner.Scan() {
-		if err := scanner.Err(); err != nil {
-			return "", fmt.Errorf("error reading input: %w", err)
-		}
-		// If input stream ends without providing any data (i.e. Ctrl+D)
-		return "", fmt.Errorf("no input provided")
+	defaultPath := defaultFilePath
+	if _, err := os.Stat(defaultPath); err != nil && len(choices) > 0 {
+		defaultPath = displayPath(choices[0])
 	}
 
-	input := strings.TrimSpace(scanner.Text())
+	if len(choices) > 0 {
+		fmt.Fprintln(stdout, "Quizzes:")
+		writeQuizMenu(ctx, stdout, choices, recent)
+		fmt.Fprintf(stdout, "Enter file path or number [%s]: ", defaultPath)
+	} else {
+		fmt.Fprintf(stdout, "Enter file path [%s]: ", defaultPath)
+	}
+
+	line, err := recordAnswer(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	input := strings.TrimSpace(line)
 	if input == "" {
-		return defaultFilePath, nil
+		input = defaultPath
+	}
+
+	// A number picks from the list, unless a file of that name exists.
+	if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(choices) {
+		if _, err := os.Stat(input); err != nil {
+			return choices[n-1], nil
+		}
 	}
 
 	expandedPath, err := filepath.Abs(input)
@@ -97,7 +272,7 @@ func getFilePath() (string, error) {
 	}
 
 	if _, err := os.Stat(expandedPath); errors.Is(err, fs.ErrNotExist) {
-		return "", fmt.Errorf("file does not exist: %v", expandedPath)
+		return "", fmt.Errorf("%w: %v", fs.ErrNotExist, expandedPath)
 	}
 
 	return expandedPath, nil
@@ -108,71 +283,56 @@ func getFilePath() (string, error) {
 //
 // Parameters:
 //   - filePath: a string representing the path to the CSV file to be read.
+//   - strict: whether a malformed row aborts reading instead of being skipped.
 //
 // Returns:
 //   - [][]string: a slice of string slices, where each inner slice represents a row
 //     from the CSV file (excluding the header row).
 //   - []string: a slice of strings representing the headers from the first row of the CSV file.
+//   - []rowError: the malformed rows that were skipped; always empty in strict mode.
 //   - error: an error if any step of the reading process fails.
 //
 // Note:
 //   - This function assumes that the CSV file has at a header row.
-//   - The expected CSV schema is: question | answer
-func readCSV(filePath string) ([][]string, []string, error) {
+//   - Rows must have as many fields as the header row.
+//   - Lines starting with '#' before the header row are comments, including the settings
+//     (see readQuizSettings); later rows are read verbatim.
+//   - The expected CSV schema is: question | answer [| category] [| romanization]
+//     [| question_<lang> | answer_<lang> ...]
+func readCSV(filePath string, strict bool) ([][]string, []string, []rowError, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error opening file: %w", err)
+		return nil, nil, nil, fmt.Errorf("error opening file: %w", err)
 	}
 	defer file.Close()
 
 	reader := csv.NewReader(file)
-
-	headers, err := reader.Read()
+	headers, err := readHeader(reader)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading headers: %w", err)
+		return nil, nil, nil, fmt.Errorf("error reading headers: %w", err)
 	}
 
-	records, err := reader.ReadAll()
+	var records [][]string
+	skipped, err := readRows(reader, file, strict, func(_ int64, record []string) {
+		records = append(records, record)
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading records: %w", err)
+		return nil, nil, nil, fmt.Errorf("error reading records: %w", err)
 	}
 
-	return records, headers, nil
+	return records, headers, skipped, nil
 }
 
 // recordAnswer prompts the user for input and returns the entered string.
 //
 // This function reads a single line of text from standard input.
 //
+// Parameters:
+//   - ctx: the context; reading gives up when it is done.
+//
 // Returns:
 //   - answer: a string containing the user's input, with leading and trailing whitespace removed.
 //   - err: an error if the input operation fails or if no input is provided.
-func recordAnswer() (answer string, err error) {
-	scanner := bufio.NewScanner(os.Stdin)
-	if !scanner.Scan() {
-		if err := scanner.Err(); err != nil {
-			return "", fmt.Errorf("error reading input: %w", err)
-		}
-		// If input stream ends without providing any data (i.e. Ctrl+D)
-		return "", fmt.Errorf("no input provided")
-	}
-	answer = scanner.Text()
-	return answer, nil
-}
-
-// calculateScore compares user answers to correct answers and returns the number of correct responses.
-// It takes two parameters:
-//   - userAnswers: a slice of strings representing the user's answers
-//   - correctAnswers: a slice of strings representing the correct answers
-//
-// The function assumes that both slices have the same length and correspond to each other.
-// It returns an integer representing the number of correct answers.
-func calculateScore(userAnswers, correctAnswers []string) int {
-	userPoints := 0
-	for i, v := range userAnswers {
-		if v == correctAnswers[i] {
-			userPoints++
-		}
-	}
-	return userPoints
+func recordAnswer(ctx context.Context) (answer string, err error) {
+	return stdin.readLine(ctx, systemClock{}, 0)
 }
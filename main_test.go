@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadCSVComments(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		records [][]string
+		skipped []int
+	}{
+		{
+			name:    "settings before the header",
+			content: "# title: Primes\n# shuffle: true\n\nquestion,answer\n2nd prime,3\n",
+			records: [][]string{{"2nd prime", "3"}},
+		},
+		{
+			name:    "question starting with the comment character",
+			content: "question,answer\n2nd prime,3\n#1 prime after 2,3\n",
+			records: [][]string{{"2nd prime", "3"}, {"#1 prime after 2", "3"}},
+		},
+		{
+			name:    "comment line among the rows",
+			content: "# title: Primes\nquestion,answer\n2nd prime,3\n# reviewed 2026\n3rd prime,5\n",
+			records: [][]string{{"2nd prime", "3"}, {"3rd prime", "5"}},
+			skipped: []int{4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "quiz.csv")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			records, headers, skipped, err := readCSV(path, false)
+			if err != nil {
+				t.Fatalf("readCSV: %v", err)
+			}
+			if !slices.Equal(headers, []string{"question", "answer"}) {
+				t.Errorf("headers = %q, want question, answer", headers)
+			}
+			if !slices.EqualFunc(records, tt.records, slices.Equal) {
+				t.Errorf("records = %q, want %q", records, tt.records)
+			}
+			var lines []int
+			for _, s := range skipped {
+				lines = append(lines, s.Line)
+			}
+			if !slices.Equal(lines, tt.skipped) {
+				t.Errorf("skipped lines = %v, want %v", lines, tt.skipped)
+			}
+		})
+	}
+}
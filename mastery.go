@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// defaultMasteryStreak is the number of correct answers in a row, across
+// sessions, after which a question counts as mastered.
+const defaultMasteryStreak = 3
+
+// masteryKey identifies a question for mastery: its text in the quiz file and
+// the way round it is asked, as each direction is mastered separately.
+type masteryKey struct {
+	Question string
+	Reversed bool
+}
+
+// masteryReset forgets a user's answers to a quiz, or to one of its
+// questions, up to the time of the reset.
+type masteryReset struct {
+	User string `json:"user"`
+	// Question is the text of the question reset; empty resets them all.
+	Question string    `json:"question,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// loadMasteryResets reads the mastery resets of a quiz from the storage in
+// use. A quiz that was never reset has none.
+func loadMasteryResets(ctx context.Context, quiz string) ([]masteryReset, error) {
+	store, err := openStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.loadMasteryResets(ctx, quiz)
+}
+
+// saveMasteryResets replaces the mastery resets of a quiz in the storage in use.
+func saveMasteryResets(ctx context.Context, quiz string, resets []masteryReset) error {
+	store, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	return store.saveMasteryResets(ctx, quiz, resets)
+}
+
+// loadMasteryResets reads the mastery resets of a quiz from its file in the
+// state directory.
+func (fileStorage) loadMasteryResets(_ context.Context, quiz string) ([]masteryReset, error) {
+	path, err := quizStatePath("mastery", quiz, ".json")
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading mastery state: %w", err)
+	}
+	var resets []masteryReset
+	if err := json.Unmarshal(data, &resets); err != nil {
+		return nil, fmt.Errorf("error parsing mastery state: %w", err)
+	}
+	return resets, nil
+}
+
+// saveMasteryResets writes the mastery resets of a quiz to its file in the
+// state directory, creating the directory if needed.
+func (fileStorage) saveMasteryResets(_ context.Context, quiz string, resets []masteryReset) error {
+	path, err := quizStatePath("mastery", quiz, ".json")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(resets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding mastery state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing mastery state: %w", err)
+	}
+	return nil
+}
+
+// masteryStreaks counts, for each question a user has answered, how many
+// times in a row they have answered it correctly up to their latest attempt.
+//
+// Parameters:
+//   - entries: the attempts at a quiz, oldest first.
+//   - user: the user whose answers count.
+//   - resets: the quiz's mastery resets; answers given before a reset that
+//     applies to them are ignored.
+//
+// Returns:
+//   - map[masteryKey]int: the current streak of each question answered.
+func masteryStreaks(entries []historyEntry, user string, resets []masteryReset) map[masteryKey]int {
+	streaks := make(map[masteryKey]int)
+	for _, e := range entries {
+		if e.User != user {
+			continue
+		}
+		for _, outcome := range e.Questions {
+			if resetSince(resets, user, outcome.Question, e.Time) {
+				continue
+			}
+			key := masteryKey{Question: outcome.Question, Reversed: outcome.Reversed}
+			if outcome.Correct {
+				streaks[key]++
+			} else {
+				streaks[key] = 0
+			}
+		}
+	}
+	return streaks
+}
+
+// resetSince reports whether the user's mastery of question was reset after an answer given at t.
+func resetSince(resets []masteryReset, user, question string, t time.Time) bool {
+	for _, r := range resets {
+		if r.User == user && (r.Question == "" || r.Question == question) && !t.After(r.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropMastered leaves out the questions the current user has mastered.
+//
+// Parameters:
+//   - ctx: the context of the requests to a storage server, if one is used.
+//   - filePath: the path of the quiz file.
+//   - questions: the questions that would be asked.
+//   - streak: the number of correct answers in a row that makes a question mastered.
+//
+// Returns:
+//   - []question: the questions not yet mastered, in the same order.
+//   - int: the number of questions left out.
+//   - error: an error if the history or mastery state cannot be read.
+func dropMastered(ctx context.Context, filePath string, questions []question, streak int) ([]question, int, error) {
+	quiz, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error expanding path: %w", err)
+	}
+	entries, err := loadHistory(ctx, quiz)
+	var resets []masteryReset
+	if err == nil {
+		resets, err = loadMasteryResets(ctx, quiz)
+	}
+	if errors.Is(err, errUnreachable) {
+		slog.Warn(fmt.Sprintf("asking mastered questions too: %v", err))
+		return questions, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	streaks := masteryStreaks(entries, currentUser(), resets)
+	kept := questions[:0:0]
+	for _, q := range questions {
+		if streaks[masteryKey{Question: q.fileText(), Reversed: q.Reversed}] < streak {
+			kept = append(kept, q)
+		}
+	}
+	return kept, len(questions) - len(kept), nil
+}
+
+// runMastery implements the "mastery" subcommand.
+//
+// It lists how close a user is to mastering each question of a quiz, that is
+// to answering it correctly -streak times in a row, or with -reset forgets
+// their answers so far so that every question, or the one given with
+// -question, is asked again.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the quiz, history or mastery state cannot be read or written.
+func runMastery(ctx context.Context, args []string) error {
+	flags := newFlagSet("mastery")
+	streak := flags.Int("streak", defaultMasteryStreak, "`n` correct answers in a row, across sessions, master a question")
+	user := flags.String("user", currentUser(), "show or reset the mastery of `name`")
+	reset := flags.Bool("reset", false, "forget the user's answers so far, so mastered questions are asked again")
+	only := flags.String("question", "", "with -reset, reset only the question with this `text`")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *streak < 1 || (*only != "" && !*reset) {
+		return usageErrorf("usage: go-quiz mastery [-streak n] [-user name] [-reset [-question text]] quiz.csv")
+	}
+
+	quiz, err := filepath.Abs(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error expanding path: %w", err)
+	}
+	resets, err := loadMasteryResets(ctx, quiz)
+	if err != nil {
+		return err
+	}
+
+	if *reset {
+		resets = append(resets, masteryReset{User: *user, Question: *only, Time: time.Now()})
+		if err := saveMasteryResets(ctx, quiz, resets); err != nil {
+			return err
+		}
+		if *only != "" {
+			fmt.Fprintf(stdout, "Reset the mastery of %q for %s.\n", *only, *user)
+		} else {
+			fmt.Fprintf(stdout, "Reset the mastery of every question for %s.\n", *user)
+		}
+		return nil
+	}
+
+	questions, _, err := loadQuiz(quiz, loadOptions{}, slog.Warn)
+	if err != nil {
+		return err
+	}
+	entries, err := loadHistory(ctx, quiz)
+	if err != nil {
+		return err
+	}
+	streaks := masteryStreaks(entries, *user, resets)
+
+	mastered, listed := 0, 0
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STREAK\tMASTERED\tQUESTION")
+	for _, q := range questions {
+		for _, reversed := range []bool{false, true} {
+			n, answered := streaks[masteryKey{Question: q.Text, Reversed: reversed}]
+			if reversed && !answered {
+				// Only list the reverse direction of questions asked that way round.
+				continue
+			}
+			listed++
+			mark := "no"
+			if n >= *streak {
+				mark = "yes"
+				mastered++
+			}
+			text := q.Text
+			if reversed {
+				text += " (reverse)"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", n, mark, abbreviate(text, terminalWidth()-20))
+		}
+	}
+	w.Flush()
+	fmt.Fprintf(stdout, "%s has mastered %d of %d.\n", *user, mastered, listed)
+	return nil
+}
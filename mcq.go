@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strings"
+)
+
+// runMakeMCQ implements the "make-mcq" subcommand.
+//
+// It converts a question-and-answer bank into a multiple-choice quiz by
+// adding a choices column. The wrong choices (distractors) for a question are
+// other answers from the bank, preferably from the same category.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the bank cannot be read or written.
+func runMakeMCQ(ctx context.Context, args []string) error {
+	flags := newFlagSet("make-mcq")
+	choices := flags.Int("n", 4, "number of choices per question, including the answer")
+	output := flags.String("o", "", "write the quiz to `file` instead of standard output")
+	seed := flags.Uint64("seed", 0, "random `seed`, for reproducible output (0 picks one at random)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *choices < 2 {
+		return usageErrorf("usage: go-quiz make-mcq [-n choices] [-seed n] [-o file] bank.csv")
+	}
+
+	records, headers, skipped, err := readQuizRecords(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		slog.Warn(formatSkipped(skipped))
+	}
+
+	questions, err := parseQuestions(records, headers, "")
+	if err != nil {
+		return err
+	}
+
+	rng := rand.New(rand.NewPCG(*seed, *seed))
+	if *seed == 0 {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+
+	short := 0
+	options := make([][]string, len(questions))
+	for i, q := range questions {
+		options[i] = distractors(questions, i, *choices-1, rng)
+		if len(options[i]) < *choices-1 {
+			short++
+		}
+		options[i] = append(options[i], q.Answer)
+		rng.Shuffle(len(options[i]), func(a, b int) {
+			options[i][a], options[i][b] = options[i][b], options[i][a]
+		})
+	}
+	if short > 0 {
+		slog.Warn(fmt.Sprintf("%d question(s) have fewer than %d choices, as the bank has too few distinct answers", short, *choices))
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("error creating output: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	// Keep the quiz's settings and other leading comments.
+	settings, err := readQuizSettings(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := settings.writePreamble(w); err != nil {
+		return fmt.Errorf("error writing quiz: %w", err)
+	}
+	return writeMCQ(w, records, headers, options)
+}
+
+// distractors picks up to n wrong choices for questions[i] from the other
+// answers in the bank. Answers from the same category are used first, then
+// any others; answers equal to the right one, ignoring case and surrounding
+// space, and duplicates are never picked.
+func distractors(questions []question, i, n int, rng *rand.Rand) []string {
+	q := questions[i]
+	seen := map[string]bool{normalizeChoice(q.Answer): true}
+
+	var sameCategory, others []string
+	for j, other := range questions {
+		key := normalizeChoice(other.Answer)
+		if j == i || seen[key] || key == "" || strings.Contains(other.Answer, choiceSeparator) {
+			continue
+		}
+		seen[key] = true
+		if other.Category == q.Category {
+			sameCategory = append(sameCategory, other.Answer)
+		} else {
+			others = append(others, other.Answer)
+		}
+	}
+
+	picked := sample(sameCategory, n, rng)
+	return append(picked, sample(others, n-len(picked), rng)...)
+}
+
+// sample returns up to n items of pool in random order.
+func sample(pool []string, n int, rng *rand.Rand) []string {
+	if n <= 0 {
+		return nil
+	}
+	pool = slices.Clone(pool)
+	rng.Shuffle(len(pool), func(a, b int) { pool[a], pool[b] = pool[b], pool[a] })
+	return pool[:min(n, len(pool))]
+}
+
+// normalizeChoice returns the form in which two choices are compared for being the same.
+func normalizeChoice(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// writeMCQ writes the bank as CSV with each question's choices in the choices
+// column, replacing the column if the bank already has one.
+func writeMCQ(w io.Writer, records [][]string, headers []string, options [][]string) error {
+	choicesCol := columnIndex(headers, "choices")
+	if choicesCol < 0 {
+		headers = append(slices.Clone(headers), "choices")
+		choicesCol = len(headers) - 1
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf("error writing quiz: %w", err)
+	}
+	for i, record := range records {
+		row := slices.Clone(record)
+		for len(row) < len(headers) {
+			row = append(row, "")
+		}
+		row[choicesCol] = strings.Join(options[i], choiceSeparator)
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("error writing quiz: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing quiz: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+)
+
+// missWeight is the weight a miss in the user's last session adds to a
+// question's chance of being picked with -miss-decay, on top of the weight
+// of 1 every question has.
+const missWeight = 4
+
+// missWeights returns how strongly each question should be favoured for
+// having been missed recently by a user.
+//
+// Parameters:
+//   - entries: the attempts at a quiz, oldest first.
+//   - user: the user taking the quiz.
+//   - decay: the share of a miss's weight kept for each of the user's
+//     sessions since, from 0 to 1.
+//
+// Returns:
+//   - map[masteryKey]float64: the weight of each question missed, 1 plus
+//     missWeight for each miss, shrunk by decay for every session since.
+func missWeights(entries []historyEntry, user string, decay float64) map[masteryKey]float64 {
+	weights := make(map[masteryKey]float64)
+	age := 0
+	for _, e := range slices.Backward(entries) {
+		if e.User != user {
+			continue
+		}
+		for _, outcome := range e.Questions {
+			if !outcome.Correct {
+				key := masteryKey{Question: outcome.Question, Reversed: outcome.Reversed}
+				weights[key] = max(weights[key], 1) + missWeight*math.Pow(decay, float64(age))
+			}
+		}
+		age++
+	}
+	return weights
+}
+
+// weightByMisses puts the questions in a random order in which those the
+// user missed recently tend to come first, so that with -limit they are
+// more likely to be asked. Questions are ordered by weighted random sampling
+// without replacement (see missWeights), each one never missed having a
+// weight of 1.
+//
+// Parameters:
+//   - ctx: the context of the request to a storage server, if one is used.
+//   - filePath: the quiz file the questions come from.
+//   - questions: the questions.
+//   - decay: the decay factor given with -miss-decay.
+//
+// Returns:
+//   - []question: the questions, in the order to ask them.
+//   - error: an error if the history cannot be read.
+func weightByMisses(ctx context.Context, filePath string, questions []question, decay float64) ([]question, error) {
+	quiz, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error expanding path: %w", err)
+	}
+	entries, err := loadHistory(ctx, quiz)
+	if errors.Is(err, errUnreachable) {
+		slog.Warn(fmt.Sprintf("not favouring missed questions: %v", err))
+		entries, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	weights := missWeights(entries, currentUser(), decay)
+
+	// Each question draws a key of u^(1/weight), u being uniform in [0, 1);
+	// taking the questions by key, highest first, samples them in
+	// proportion to their weights.
+	keys := make([]float64, len(questions))
+	order := make([]int, len(questions))
+	for i, q := range questions {
+		w := max(weights[masteryKey{Question: q.fileText(), Reversed: q.Reversed}], 1)
+		keys[i] = math.Pow(rand.Float64(), 1/w)
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int { return cmp.Compare(keys[b], keys[a]) })
+
+	weighted := make([]question, 0, len(questions))
+	for _, i := range order {
+		weighted = append(weighted, questions[i])
+	}
+	return weighted, nil
+}
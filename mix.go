@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"path/filepath"
+)
+
+// Ways in which mix interleaves the questions of several quizzes.
+const (
+	interleaveRoundRobin   = "round-robin"
+	interleaveProportional = "proportional"
+)
+
+// interleave merges the questions of several quizzes into one session.
+//
+// Parameters:
+//   - quizzes: the questions of each quiz, in the order each is to be asked.
+//   - how: round-robin takes a question from each quiz in turn, skipping
+//     quizzes that have run out; proportional picks the quiz of each question
+//     at random, weighted by the number of questions it has left, so larger
+//     quizzes are spread evenly through the session.
+//
+// Returns:
+//   - []question: the questions of all the quizzes, interleaved.
+func interleave(quizzes [][]question, how string) []question {
+	var session []question
+	next := make([]int, len(quizzes))
+	remaining := 0
+	for _, q := range quizzes {
+		remaining += len(q)
+	}
+
+	for i := 0; remaining > 0; i = (i + 1) % len(quizzes) {
+		if how == interleaveProportional {
+			// Pick the quiz holding the n-th of the remaining questions.
+			n := rand.IntN(remaining)
+			for i = 0; n >= len(quizzes[i])-next[i]; i++ {
+				n -= len(quizzes[i]) - next[i]
+			}
+		}
+		if next[i] == len(quizzes[i]) {
+			continue
+		}
+		session = append(session, quizzes[i][next[i]])
+		next[i]++
+		remaining--
+	}
+	return session
+}
+
+// runMix implements the "mix" subcommand.
+//
+// It runs one session interleaving the questions of several quizzes, as
+// mixing topics makes for better practice than taking them one at a time.
+// The score is broken down per quiz, and each quiz's part of the session is
+// recorded in the history as an attempt at that quiz.
+//
+// The quiz flags apply to every quiz, and -limit to the session as a whole.
+// The quizzes' own settings are not applied, as they may conflict.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or a quiz cannot be loaded.
+func runMix(ctx context.Context, args []string) error {
+	flags := newFlagSet("mix")
+	opts := registerQuizFlags(flags)
+	how := flags.String("interleave", interleaveRoundRobin, "how to interleave the quizzes: round-robin or proportional")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() < 2 {
+		return usageErrorf("usage: go-quiz mix [-interleave round-robin|proportional] [quiz flags] quiz1.csv quiz2.csv...")
+	}
+	if *how != interleaveRoundRobin && *how != interleaveProportional {
+		return fmt.Errorf("invalid -interleave %q: use round-robin or proportional", *how)
+	}
+	if opts.load.Indexed {
+		return fmt.Errorf("-indexed cannot be used with mix")
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, arg := range flags.Args() {
+		filePath, err := filepath.Abs(arg)
+		if err != nil {
+			return fmt.Errorf("error expanding path: %w", err)
+		}
+		paths = append(paths, filePath)
+	}
+	loaded, err := loadQuizzes(ctx, paths, opts.load, slog.Warn)
+	if err != nil {
+		return err
+	}
+
+	var quizzes [][]question
+	byCategory := false
+	for i, filePath := range paths {
+		questions, headers := loaded[i].questions, loaded[i].headers
+		byCategory = byCategory || columnIndex(headers, "category") >= 0
+
+		questions = applyDirection(questions, opts.direction)
+		if opts.mastery > 0 {
+			if questions, _, err = dropMastered(ctx, filePath, questions, opts.mastery); err != nil {
+				return err
+			}
+		}
+		if opts.missDecay > 0 {
+			if questions, err = weightByMisses(ctx, filePath, questions, opts.missDecay); err != nil {
+				return err
+			}
+		}
+		for i := range questions {
+			questions[i].Quiz = filePath
+		}
+		quizzes = append(quizzes, selectQuestions(questions, opts.load.Shuffle && opts.missDecay == 0, shuffleConstraints{}, 0))
+		fmt.Fprintf(stdout, "Using %s: %d questions\n", displayPath(filePath), len(questions))
+	}
+
+	questions := selectQuestions(interleave(quizzes, *how), false, shuffleConstraints{}, opts.load.Limit)
+	if len(questions) == 0 {
+		return fmt.Errorf("no questions to ask")
+	}
+
+	// Each quiz's part of the session is recorded against it (see
+	// session.recordAttempts); there is no one quiz to certify.
+	return runSession(ctx, opts, flags, session{
+		questions:  questions,
+		byCategory: byCategory,
+		attempt:    &historyEntry{},
+	})
+}
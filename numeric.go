@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// toleranceBand gives credit for a numeric answer within some distance of the expected one.
+type toleranceBand struct {
+	// Width is the largest accepted distance from the answer, as a
+	// percentage of the answer if Relative is set.
+	Width    float64
+	Relative bool
+	// Credit is the share of a point given, from 0 to 1.
+	Credit float64
+}
+
+// parseTolerance parses the tolerance column of a numeric question: bands
+// separated by spaces, each a width optionally followed by a colon and the
+// credit it gives (a full point by default). A width ending in "%" is
+// relative to the answer. For example, "2% 10%:0.5" gives full credit within
+// 2% of the answer and half credit within 10%, and "0.5" accepts answers off
+// by at most 0.5.
+//
+// Returns:
+//   - []toleranceBand: the bands in the order given; none for a blank value.
+//   - error: an error if a band is malformed.
+func parseTolerance(spec string) ([]toleranceBand, error) {
+	var bands []toleranceBand
+	for _, field := range strings.Fields(spec) {
+		width, credit, hasCredit := strings.Cut(field, ":")
+		band := toleranceBand{Credit: 1}
+
+		width, band.Relative = strings.CutSuffix(width, "%")
+		w, err := strconv.ParseFloat(width, 64)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid tolerance %q: width must be a non-negative number or percentage", field)
+		}
+		band.Width = w
+
+		if hasCredit {
+			c, err := strconv.ParseFloat(credit, 64)
+			if err != nil || c < 0 || c > 1 {
+				return nil, fmt.Errorf("invalid tolerance %q: credit must be between 0 and 1", field)
+			}
+			band.Credit = c
+		}
+		bands = append(bands, band)
+	}
+	return bands, nil
+}
+
+// toleranceCredit returns the credit for a numeric answer under the question's
+// tolerance bands: the most generous credit of any band the answer falls in,
+// or 0 if it falls in none or either the answer or the expected answer is
+// not a number.
+func (q question) toleranceCredit(answer string) float64 {
+	got, err := strconv.ParseFloat(strings.TrimSpace(answer), 64)
+	if err != nil {
+		return 0
+	}
+	want, err := strconv.ParseFloat(strings.TrimSpace(q.Answer), 64)
+	if err != nil {
+		return 0
+	}
+
+	distance := math.Abs(got - want)
+	credit := 0.0
+	for _, band := range q.Tolerance {
+		width := band.Width
+		if band.Relative {
+			width = band.Width / 100 * math.Abs(want)
+		}
+		if distance <= width {
+			credit = max(credit, band.Credit)
+		}
+	}
+	return credit
+}
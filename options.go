@@ -0,0 +1,214 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// quizOptions holds the command-line options for taking a quiz.
+type quizOptions struct {
+	load loadOptions
+	ask  askOptions
+	// results is the file to write per-question results to, if any.
+	results string
+	// answersFrom is the file typed input is read from instead of standard input, if any.
+	answersFrom string
+	// record is the asciinema cast file the session is recorded to, if any.
+	record string
+	// irt reports a Rasch ability estimate alongside the score.
+	irt bool
+	// pass is the score, in percent, needed to pass; 0 means there is no pass mark.
+	pass float64
+	// maxAttempts is the number of attempts each user may make; 0 allows any number.
+	maxAttempts int
+	// mastery is the streak of correct answers after which a question is
+	// left out; 0 asks mastered questions too.
+	mastery int
+	// missDecay, if set, favours the questions the user missed recently,
+	// each miss counting for this share less with every later session.
+	missDecay float64
+	// direction is the way round questions are asked (see applyDirection).
+	direction string
+	// certificate is the .txt or .pdf file a certificate is written to when
+	// the quiz, or a course, is passed, if any.
+	certificate string
+	// stopRecording stops the recording once startInput has been called.
+	stopRecording func() error
+}
+
+// registerQuizFlags defines the flags for taking a quiz and returns where their values are stored.
+// Every command that runs a quiz registers the same flags so they behave alike.
+func registerQuizFlags(flags *flag.FlagSet) *quizOptions {
+	opts := &quizOptions{}
+
+	flags.StringVar(&opts.load.Lang, "lang", "", "run the quiz in `language`, using the question_<lang> and answer_<lang> columns")
+	flags.BoolVar(&opts.load.Shuffle, "shuffle", false, "ask the questions in random order")
+	flags.StringVar(&opts.direction, "direction", directionForward,
+		"ask questions `way` round: forward, reverse (answer to question), both or mixed (random)")
+	flags.IntVar(&opts.mastery, "mastery", defaultMasteryStreak,
+		"leave out questions answered correctly `n` times in a row in past sessions (0 asks them all)")
+	flags.Float64Var(&opts.missDecay, "miss-decay", 0,
+		"pick questions at random, favouring those missed recently; each miss counts `factor` times as much a session later, e.g. 0.5 (0 is off)")
+	flags.IntVar(&opts.load.Limit, "limit", 0, "ask at most `n` questions (0 asks all)")
+	flags.BoolVar(&opts.load.StrictParse, "strict-parse", false, "abort on any malformed row instead of skipping it")
+	flags.BoolVar(&opts.load.NoHeader, "no-header", false, "the file has no header row; its first row is a question")
+	flags.BoolVar(&opts.load.Indexed, "indexed", false, "read only the questions asked, using an on-disk index (for very large files)")
+	flags.StringVar(&opts.load.Sheet, "sheet", "", "read the worksheet called `name` from an .xlsx file (default the first)")
+	flags.StringVar(&opts.load.Columns, "columns", "", "read only these `columns` of an .xlsx file, in order, e.g. B,D")
+
+	flags.BoolVar(&opts.ask.Confirm, "confirm", false, "ask for confirmation before each answer is submitted")
+	flags.DurationVar(&opts.ask.Timeout, "timeout", 0, "time allowed per question, e.g. 30s (0 means no limit)")
+	flags.StringVar(&opts.ask.TimeoutAnswer, "timeout-answer", "", "`answer` submitted when a question times out, e.g. \"skip\" (default blank)")
+	flags.BoolVar(&opts.ask.Explain, "explain", false, "after each answer, ask for an optional one-line reason, saved with -results")
+	flags.StringVar(&opts.ask.Commands, "commands", strings.Join(quizCommands, ","),
+		"`commands` that may be typed in place of an answer, e.g. calc for /calc 17*23, or none")
+	flags.DurationVar(&opts.ask.HintTime, "hint-time", 0, "give `duration` more on a question's time limit when /hint is used on it, e.g. 10s")
+	flags.Float64Var(&opts.ask.HintPenalty, "hint-penalty", 0, "share of a question's point lost by using /hint on it, from 0 to 1, e.g. 0.5")
+	flags.StringVar(&opts.ask.Cues, "cues", "", "signal timer milestones and time running out with a `cue`: bell, flash or a sound file")
+	flags.DurationVar(&opts.ask.Pace, "pace", 0, "reveal each question a word at a time, waiting `duration` between words, e.g. 600ms")
+	flags.StringVar(&opts.ask.Reveal, "reveal", revealWord, "`unit` revealed at a time with -pace: word or line")
+	flags.BoolVar(&opts.ask.Large, "large", false, "show questions in double-size letters, for young readers")
+	flags.BoolVar(&opts.ask.Spelling, "spelling", false, "run a spelling test: speak each answer, and its sentence column, without showing it")
+	flags.StringVar(&opts.ask.Revisions, "revisions", revisionsLocked,
+		"`policy` for changing answers after the last question: free, once or locked")
+	flags.BoolVar(&opts.ask.WatchFocus, "watch-focus", false,
+		"warn and note in the results whenever the terminal loses focus, on terminals that report it")
+	flags.BoolVar(&opts.ask.SuddenDeath, "sudden-death", false, "end the quiz at the first wrong answer; the questions left count as wrong")
+	flags.BoolVar(&opts.ask.Streaks, "streaks", false,
+		"score runs of correct answers higher (x1, x1.5, x2... up to x3), showing after each answer whether it was correct")
+	flags.BoolVar(&opts.ask.Proctor, "proctor", false, "hide answers as they are typed and show them only to the examiner at the end")
+
+	flags.StringVar(&opts.results, "results", "", "write per-question results to `file` (.json or .csv)")
+	flags.Float64Var(&opts.pass, "pass", 0, "report whether the score reaches the pass mark of `percent` (0 means none)")
+	flags.StringVar(&opts.certificate, "certificate", "",
+		"when the quiz or course is passed, write a certificate with a verification code to `file` (.txt or .pdf)")
+	flags.IntVar(&opts.maxAttempts, "max-attempts", 0, "refuse to start once the user has made `n` attempts at the quiz (0 means no limit)")
+	flags.BoolVar(&opts.irt, "irt", false, "also estimate ability with the Rasch model from calibrated question difficulties")
+	flags.StringVar(&opts.answersFrom, "answers-from", "",
+		"read answers and other input from `file`, e.g. a FIFO or /dev/fd/3, instead of standard input")
+	flags.StringVar(&opts.record, "record", "", "record the session to `file` in asciinema cast format")
+	return opts
+}
+
+// validate reports options that are invalid or cannot be combined.
+func (o *quizOptions) validate() error {
+	if o.load.NoHeader && o.load.Indexed {
+		return fmt.Errorf("-no-header cannot be combined with -indexed")
+	}
+	for _, name := range strings.Split(o.ask.Commands, ",") {
+		if name != "none" && !slices.Contains(quizCommands, name) {
+			return fmt.Errorf("unknown command %q in -commands: use %s or none", name, strings.Join(quizCommands, ", "))
+		}
+	}
+	if err := validateCue(o.ask.Cues); err != nil {
+		return err
+	}
+	if o.mastery < 0 {
+		return fmt.Errorf("-mastery must not be negative")
+	}
+	if o.ask.Streaks && o.ask.Proctor {
+		return fmt.Errorf("-streaks cannot be combined with -proctor, as it shows whether answers are correct")
+	}
+	if o.ask.HintTime < 0 {
+		return fmt.Errorf("-hint-time must not be negative")
+	}
+	if o.ask.HintPenalty < 0 || o.ask.HintPenalty > 1 {
+		return fmt.Errorf("-hint-penalty must be between 0 and 1")
+	}
+	if o.missDecay < 0 || o.missDecay > 1 {
+		return fmt.Errorf("-miss-decay must be between 0 and 1")
+	}
+	if err := validateDirection(o.direction); err != nil {
+		return err
+	}
+	if err := validateRevisions(o.ask.Revisions); err != nil {
+		return err
+	}
+	if o.ask.Reveal != revealWord && o.ask.Reveal != revealLine {
+		return fmt.Errorf("invalid -reveal %q: use word or line", o.ask.Reveal)
+	}
+	if o.certificate != "" {
+		if err := validateCertificatePath(o.certificate); err != nil {
+			return err
+		}
+	}
+	if o.pass < 0 || o.pass > 100 {
+		return fmt.Errorf("-pass must be a percentage between 0 and 100")
+	}
+	if o.results != "" {
+		if _, err := resultsEncoder(o.results); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openAnswerSource switches interactive input to the file given with
+// -answers-from, if any, so that a test harness or assistive tool can drive
+// the quiz while its output stays on standard output.
+//
+// Opening a FIFO blocks until another process opens it for writing. The file
+// stays open for the rest of the run.
+func (o *quizOptions) openAnswerSource() error {
+	if o.answersFrom == "" {
+		return nil
+	}
+
+	file, err := os.Open(o.answersFrom)
+	if err != nil {
+		return fmt.Errorf("error opening answer source: %w", err)
+	}
+	stdin = newLineReader(file)
+	return nil
+}
+
+// startInput opens the answer source and starts the recording, the first
+// time it is called, and returns the function stopping the recording.
+// runSession calls it; a command prompting the user before its session, such
+// as for the quiz file, calls it first so the prompt is part of both.
+func (o *quizOptions) startInput() (func() error, error) {
+	if o.stopRecording != nil {
+		return o.stopRecording, nil
+	}
+	if err := o.openAnswerSource(); err != nil {
+		return nil, err
+	}
+	stop, err := o.startRecording()
+	if err != nil {
+		return nil, err
+	}
+	o.stopRecording = stop
+	return stop, nil
+}
+
+// startRecording starts recording the session to the cast file given with
+// -record, if any, by routing interactive input and output through the
+// recorder. The returned function stops the recording and must be called
+// before the program exits.
+func (o *quizOptions) startRecording() (func() error, error) {
+	if o.record == "" {
+		return func() error { return nil }, nil
+	}
+
+	width, height := ttySize(os.Stdout)
+	if width <= 0 {
+		width = terminalWidth()
+	}
+	if height <= 0 {
+		height = 24
+	}
+
+	rec, err := newCastRecorder(o.record, width, height)
+	if err != nil {
+		return nil, err
+	}
+	stdin = castSource{src: stdin, rec: rec, hidden: &o.ask.Proctor}
+	stdout = rec.tee(stdout)
+	stderr = rec.tee(stderr)
+	// Finish the recording if the quiz is interrupted, so it can be played.
+	atInterrupt(func() { rec.Close() })
+	return rec.Close, nil
+}
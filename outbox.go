@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// outboxFileName is the file in the state directory keeping the records
+// made while the storage server could not be reached.
+const outboxFileName = "outbox.jsonl"
+
+// errUnreachable is the error of a request that never got an answer from
+// the storage server, as when it is down or the machine is offline.
+var errUnreachable = errors.New("storage server unreachable")
+
+// flushOutboxOnce makes openStorage send the outbox once a run.
+var flushOutboxOnce sync.Once
+
+// outboxRecord is a record waiting in the outbox: an attempt, or the
+// reports made during one.
+type outboxRecord struct {
+	Attempt *historyEntry    `json:"attempt,omitempty"`
+	Reports []questionReport `json:"reports,omitempty"`
+}
+
+// outboxPath returns the path of the outbox in the state directory.
+func outboxPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, outboxFileName), nil
+}
+
+// queueRecord keeps a record the storage server could not be reached for
+// at the end of the outbox, creating the state directory and file if
+// needed, to be sent on a later run.
+func queueRecord(record outboxRecord) error {
+	path, err := outboxPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating state directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening outbox: %w", err)
+	}
+	defer file.Close()
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error encoding outbox: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing outbox: %w", err)
+	}
+	slog.Warn("the storage server cannot be reached: the record is kept here and sent on a later run")
+	return nil
+}
+
+// loadOutbox reads the records waiting in the outbox. A missing file means
+// there are none.
+func loadOutbox() ([]outboxRecord, error) {
+	path, err := outboxPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening outbox: %w", err)
+	}
+	defer file.Close()
+
+	var records []outboxRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r outboxRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("error parsing outbox: %w", err)
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading outbox: %w", err)
+	}
+	return records, nil
+}
+
+// flushOutbox sends the records in the outbox to the storage server in the
+// order they were made, stopping at the first it cannot send, and keeps
+// those left for a later run. Problems are logged rather than returned, as
+// they must not stop the run that found them.
+func (s httpStorage) flushOutbox(ctx context.Context) {
+	records, err := loadOutbox()
+	if err != nil {
+		slog.Warn(err.Error())
+		return
+	}
+	sent := 0
+	for _, r := range records {
+		if r.Attempt != nil {
+			err = s.postHistory(ctx, *r.Attempt)
+		} else {
+			err = s.postReports(ctx, r.Reports)
+		}
+		if err != nil {
+			if !errors.Is(err, errUnreachable) {
+				slog.Warn(fmt.Sprintf("error sending the records kept while the storage server could not be reached: %v", err))
+			}
+			break
+		}
+		sent++
+	}
+	if sent == 0 {
+		return
+	}
+
+	path, err := outboxPath()
+	if err == nil {
+		if sent == len(records) {
+			err = os.Remove(path)
+		} else {
+			err = rewriteJSONLines(path, records[sent:])
+		}
+	}
+	if err != nil {
+		slog.Warn(fmt.Sprintf("error writing outbox, so its records may be sent again: %v", err))
+		return
+	}
+	slog.Info("outbox sent to storage server", "records", sent, "left", len(records)-sent)
+}
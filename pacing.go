@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Units in which -pace reveals question text.
+const (
+	revealWord = "word"
+	revealLine = "line"
+)
+
+// DEC line attributes that make a terminal line double width and double
+// height. The line is printed twice, with the top half then the bottom half.
+const (
+	largeTop    = "\x1b#3"
+	largeBottom = "\x1b#4"
+)
+
+// wrapColumns returns the width to wrap question text to, which is halved for
+// large text as each character takes two columns.
+func (e *engine) wrapColumns() int {
+	if e.opts.Large {
+		return max(e.columns()/2, 10)
+	}
+	return e.columns()
+}
+
+// present shows question text for young readers: revealed a word or line at a
+// time with -pace, and in double-size letters with -large. Paging is not used.
+func (e *engine) present(text string) {
+	for _, line := range strings.Split(text, "\n") {
+		units := []string{line}
+		if e.opts.Pace > 0 && e.opts.Reveal != revealLine {
+			units = splitWords(line)
+		}
+
+		shown := ""
+		for i, unit := range units {
+			shown += unit
+			if e.opts.Large {
+				// Redraw both halves, then return to the top half for the next word.
+				fmt.Fprintf(e.out, "\r%s%s\n\r%s%s\x1b[A", largeTop, shown, largeBottom, shown)
+			} else {
+				fmt.Fprint(e.out, unit)
+			}
+			if e.opts.Pace > 0 && (i < len(units)-1 || e.opts.Reveal == revealLine) {
+				<-e.clock.After(e.opts.Pace)
+			}
+		}
+
+		if e.opts.Large {
+			fmt.Fprint(e.out, "\n\n")
+		} else {
+			fmt.Fprintln(e.out)
+		}
+	}
+}
+
+// splitWords splits a line into words, each with the spaces before it, so
+// that joining them gives back the line.
+func splitWords(line string) []string {
+	var words []string
+	start := 0
+	inWord := false
+	for i, r := range line {
+		space := unicode.IsSpace(r)
+		if space && inWord {
+			words = append(words, line[start:i])
+			start = i
+		}
+		inWord = !space
+	}
+	if start < len(line) {
+		words = append(words, line[start:])
+	}
+	return words
+}
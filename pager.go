@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// show prints text, paging through it when it is taller than the output.
+//
+// Paging only happens when the output height is known, so piped and
+// scripted runs always get the full text at once.
+func (e *engine) show(text string) {
+	lines := strings.Split(text, "\n")
+
+	// Keep one line free for the answer prompt under the last page.
+	if e.height < 3 || len(lines) <= e.height-1 {
+		fmt.Fprintln(e.out, text)
+		return
+	}
+	e.page(lines, e.height-1)
+}
+
+// page shows lines one page at a time.
+//
+// After each page but the last, the user presses Enter for the next page,
+// "b" for the previous page, or "q" to skip to the end. The last page is left
+// on screen so the question can be answered.
+//
+// Parameters:
+//   - lines: the lines of text to show.
+//   - pageSize: the number of lines per page, including the status line.
+func (e *engine) page(lines []string, pageSize int) {
+	step := pageSize - 1
+	top := 0
+	for {
+		end := min(top+step, len(lines))
+		if end == len(lines) {
+			// Show a full last page so the end of the text sits right above the prompt.
+			top = max(0, len(lines)-pageSize)
+			fmt.Fprintln(e.out, strings.Join(lines[top:], "\n"))
+			return
+		}
+
+		fmt.Fprintln(e.out, strings.Join(lines[top:end], "\n"))
+		fmt.Fprintf(e.out, "-- lines %d-%d of %d: Enter next page, b back, q end --", top+1, end, len(lines))
+
+		command, err := e.in.readLine(e.ctx, e.clock, 0)
+		if err != nil {
+			top = len(lines)
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(command)) {
+		case "b":
+			top = max(0, top-step)
+		case "q":
+			top = len(lines)
+		default:
+			top = end
+		}
+	}
+}
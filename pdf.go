@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size and margins of PDF documents, in points.
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 56
+)
+
+// pdfCharWidth is the average width of a Helvetica character as a share of
+// the font size, used to wrap text without the font's metrics.
+const pdfCharWidth = 0.55
+
+// pdfMinTextSize is the smallest font size text is shrunk to to fit a box.
+const pdfMinTextSize = 7
+
+// pdfDocument lays out lines of text and rules on A4 pages, top to bottom,
+// starting a new page when one is full. It only uses the standard Helvetica
+// fonts, so text outside Latin-1 is written as question marks.
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	// y is the height of the next line on the current page, from the bottom.
+	y float64
+}
+
+// newPage starts a new page.
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// space makes room for a line of the given height, starting a new page if
+// there is not enough room left, and returns the line's baseline.
+func (d *pdfDocument) space(height float64) float64 {
+	if len(d.pages) == 0 || d.y-height < pdfMargin {
+		d.newPage()
+	}
+	d.y -= height
+	return d.y
+}
+
+// text writes text at the given size, wrapped to the page width. Every line
+// is indented by indent characters and the lines after the first by hanging more.
+func (d *pdfDocument) text(text string, size float64, bold bool, indent, hanging int) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	charWidth := size * pdfCharWidth
+	columns := int((pdfPageWidth-2*pdfMargin)/charWidth) - indent
+	for i, line := range strings.Split(wrapText(text, columns, strings.Repeat(" ", hanging)), "\n") {
+		x := pdfMargin + float64(indent)*charWidth
+		if i > 0 {
+			line = strings.TrimLeft(line, " ")
+			x += float64(hanging) * charWidth
+		}
+		y := d.space(size * 1.4)
+		fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %g Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(line))
+	}
+}
+
+// rule draws a line across the page for a handwritten answer, leaving room above it to write.
+func (d *pdfDocument) rule() {
+	y := d.space(24)
+	fmt.Fprintf(d.pages[len(d.pages)-1], "0.5 w %d %.2f m %d %.2f l S\n", pdfMargin, y, pdfPageWidth-pdfMargin, y)
+}
+
+// gap leaves blank space of the given height.
+func (d *pdfDocument) gap(height float64) {
+	d.space(height)
+}
+
+// cutBox draws a dashed rectangle on the current page, as a line to cut
+// along, with its lower left corner at x, y.
+func (d *pdfDocument) cutBox(x, y, width, height float64) {
+	fmt.Fprintf(d.pages[len(d.pages)-1], "0.5 w [4 3] 0 d %.2f %.2f %.2f %.2f re S [] 0 d\n", x, y, width, height)
+}
+
+// centredText writes text on the current page, wrapped and centred in the
+// box with its lower left corner at x, y. Text too long for the box at the
+// given size is made smaller, down to pdfMinTextSize, and then runs over.
+func (d *pdfDocument) centredText(text string, size float64, bold bool, x, y, width, height float64) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	var lines []string
+	for ; ; size-- {
+		lines = strings.Split(wrapText(text, int(width/(size*pdfCharWidth)), ""), "\n")
+		if float64(len(lines))*size*1.4 <= height || size <= pdfMinTextSize {
+			break
+		}
+	}
+
+	lineHeight := size * 1.4
+	top := y + (height+float64(len(lines))*lineHeight)/2
+	for i, line := range lines {
+		lineWidth := float64(displayWidth(line)) * size * pdfCharWidth
+		fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %g Tf %.2f %.2f Td (%s) Tj ET\n",
+			font, size, x+(width-lineWidth)/2, top-float64(i+1)*lineHeight+size*0.3, pdfString(line))
+	}
+}
+
+// pdfString escapes text for a PDF string in WinAnsi encoding.
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= ' ' && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			// Latin-1 letters have the same codes in WinAnsi; write them as octal escapes.
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// writeTo writes the document as a PDF file.
+func (d *pdfDocument) writeTo(w io.Writer) error {
+	if len(d.pages) == 0 {
+		d.newPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1 to 4 are the catalog, page tree and fonts; each page then
+	// takes two, the page and its content.
+	out.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	if _, err := w.Write(out.Bytes()); err != nil {
+		return fmt.Errorf("error writing PDF: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// hiddenAnswer is shown in place of each answer in proctor mode.
+const hiddenAnswer = "[answer recorded]"
+
+// hideTypedInput stops the terminal from echoing what the quizzee types, for
+// proctor mode. It does nothing when standard input is not a terminal, as
+// input that is not typed is not shown anyway.
+//
+// Returns:
+//   - func(): restores echoing; safe to call more than once.
+//   - error: an error if echoing cannot be turned off.
+func hideTypedInput() (func(), error) {
+	if cols, _ := ttySize(os.Stdin); cols == 0 {
+		return func() {}, nil
+	}
+	if err := setEcho(os.Stdin, false); err != nil {
+		return nil, fmt.Errorf("error hiding typed input: %w", err)
+	}
+
+	// Restore echoing if the quiz is interrupted, or the terminal is left without it.
+	atInterrupt(func() { setEcho(os.Stdin, true) })
+
+	restored := false
+	return func() {
+		if restored {
+			return
+		}
+		restored = true
+		setEcho(os.Stdin, true)
+	}, nil
+}
+
+// awaitExaminer asks for the device to be handed to the examiner and waits
+// for them to press Enter before the results are shown.
+func (e *engine) awaitExaminer() error {
+	fmt.Fprintln(e.out, "Quiz complete. Please hand over to the examiner.")
+	fmt.Fprint(e.out, "Examiner: press Enter to see the answers and results. ")
+	if _, err := e.readLine(time.Time{}, 0); err != nil {
+		return err
+	}
+	fmt.Fprintln(e.out)
+	return nil
+}
+
+// writeReview lists every question with the answer given and, for wrong
+// answers, the expected one, for the examiner in proctor mode. Answers are
+// marked ✓ for full credit, ~ for partial credit and ✗ otherwise.
+//
+// Parameters:
+//   - w: where to write the review.
+//   - responses: the responses to review, in the order asked.
+//   - width: the width to shorten questions and answers to.
+func writeReview(w io.Writer, responses []response, width int) {
+	fmt.Fprintln(w, "Answers:")
+	for i, r := range responses {
+		mark := "✗"
+		if r.correct() {
+			mark = "✓"
+		} else if r.credit() > 0 {
+			mark = "~"
+		}
+		fmt.Fprintf(w, "  %s %d. %s\n", mark, i+1, abbreviate(r.Question.Text, width-7))
+		line := "       answered: " + displayText(strings.TrimSpace(r.Answer))
+		if !r.correct() {
+			line += "  expected: " + displayText(r.Question.Answer)
+		}
+		fmt.Fprintln(w, abbreviate(line, width))
+		if r.Explanation != "" {
+			fmt.Fprintln(w, abbreviate("       reason: "+displayText(r.Explanation), width))
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Environment variables configuring where quizzes are published.
+const (
+	publishURLEnv   = "GO_QUIZ_PUBLISH_URL"
+	publishTokenEnv = "GO_QUIZ_PUBLISH_TOKEN"
+)
+
+// publishPackage is the JSON document submitted to a registry's publish endpoint.
+// Quiz.URL is left empty for the registry to fill in once the quiz is accepted.
+type publishPackage struct {
+	Quiz    registryEntry `json:"quiz"`
+	Content string        `json:"content"`
+}
+
+// runPublish implements the "publish" subcommand.
+//
+// It validates a quiz file, packages it with its metadata and checksum, and
+// submits the package to a registry's publish endpoint with an HTTP POST.
+// The token in $GO_QUIZ_PUBLISH_TOKEN, if set, is sent as a bearer token.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, the quiz fails
+//     validation, or the endpoint does not accept it.
+func runPublish(ctx context.Context, args []string) error {
+	flags := newFlagSet("publish")
+	endpoint := flags.String("endpoint", os.Getenv(publishURLEnv),
+		"publish endpoint `URL` (default $"+publishURLEnv+")")
+	name := flags.String("name", "", "registry name of the quiz (default: the file name without extension)")
+	title := flags.String("title", "", "title of the quiz (default: the name)")
+	description := flags.String("description", "", "short description of the quiz")
+	author := flags.String("author", currentUser(), "author of the quiz")
+	dryRun := flags.Bool("dry-run", false, "validate and print the package metadata without submitting it")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageErrorf("usage: go-quiz publish [-endpoint URL] [-name name] [-title title] " +
+			"[-description text] [-author name] [-dry-run] quiz.csv")
+	}
+	filePath := flags.Arg(0)
+
+	if *name == "" {
+		*name = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	}
+	if *title == "" {
+		*title = *name
+	}
+	if *endpoint == "" && !*dryRun {
+		return fmt.Errorf("no publish endpoint configured: use -endpoint or set $%s", publishURLEnv)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+
+	questions, err := validateForPublish(filePath)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(content)
+	pkg := publishPackage{
+		Quiz: registryEntry{
+			Name:        *name,
+			Title:       *title,
+			Description: *description,
+			Author:      *author,
+			Questions:   len(questions),
+			Categories:  quizCategories(questions),
+			SHA256:      hex.EncodeToString(sum[:]),
+		},
+		Content: string(content),
+	}
+
+	if *dryRun {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(pkg.Quiz)
+	}
+
+	if err := submitPackage(ctx, *endpoint, os.Getenv(publishTokenEnv), pkg); err != nil {
+		return err
+	}
+
+	fmt.Printf("Published %s (%d questions, sha256 %s)\n", pkg.Quiz.Name, pkg.Quiz.Questions, pkg.Quiz.SHA256)
+	return nil
+}
+
+// validateForPublish checks that a quiz file is fit to share.
+//
+// Unlike when taking a quiz, a published file must parse without any
+// malformed rows, must have a header row, and every question needs both
+// text and an answer.
+func validateForPublish(filePath string) ([]question, error) {
+	records, headers, _, err := readCSV(filePath, true)
+	if err != nil {
+		return nil, err
+	}
+	if looksHeaderless(headers, records) {
+		return nil, fmt.Errorf("the first row %q looks like a question; published quizzes need a header row",
+			strings.Join(headers, ","))
+	}
+
+	questions, err := parseQuestions(records, headers, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("the quiz has no questions")
+	}
+
+	for i, q := range questions {
+		if strings.TrimSpace(q.Text) == "" || strings.TrimSpace(q.Answer) == "" {
+			return nil, fmt.Errorf("question %d has an empty question or answer", i+1)
+		}
+	}
+	return questions, nil
+}
+
+// quizCategories returns the distinct non-empty categories of questions, sorted.
+func quizCategories(questions []question) []string {
+	var categories []string
+	for _, q := range questions {
+		if q.Category != "" && !slices.Contains(categories, q.Category) {
+			categories = append(categories, q.Category)
+		}
+	}
+	slices.Sort(categories)
+	return categories
+}
+
+// submitPackage posts a package to a publish endpoint as JSON, giving up if
+// ctx is done first.
+func submitPackage(ctx context.Context, endpoint, token string, pkg publishPackage) error {
+	body, err := json.Marshal(pkg)
+	if err != nil {
+		return fmt.Errorf("error encoding package: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := http.Client{Timeout: registryTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error submitting quiz: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("error submitting quiz: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isTextQuiz reports whether a quiz file is in the plain text Q:/A: format,
+// judging by its extension.
+func isTextQuiz(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), ".txt")
+}
+
+// readQA reads a plain text quiz, where each question is a block of lines
+// separated from the next by a blank line:
+//
+//	Q: What is the capital of France?
+//	A: Paris
+//
+// The Q: and A: prefixes are case-insensitive. A line without a prefix
+// continues the question or answer above it, joined with a space, so long
+// questions can be wrapped. A question mark ending the question is dropped,
+// as one is added when the question is asked. Lines starting with '#' before the
+// first question are comments, including the settings (see readQuizSettings);
+// later ones are read as text.
+//
+// Parameters:
+//   - filePath: the path to the text file.
+//   - strict: whether a malformed block is an error instead of being skipped.
+//
+// Returns:
+//   - [][]string: a question and answer per block, as if read from a CSV file.
+//   - []string: the header row, "question" and "answer".
+//   - []rowError: the malformed blocks that were skipped; always empty in strict mode.
+//   - error: an error if the file cannot be read, or a block is malformed in strict mode.
+func readQA(filePath string, strict bool) ([][]string, []string, []rowError, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	var records [][]string
+	var skipped []rowError
+
+	type qaBlock struct {
+		start   int
+		first   string
+		fields  [2][]string
+		seen    [2]bool
+		last    int
+		problem string
+	}
+	// block is the question being read, if any.
+	var block *qaBlock
+
+	finish := func() error {
+		b := block
+		block = nil
+		switch {
+		case b == nil:
+			return nil
+		case b.problem != "":
+		case !b.seen[0]:
+			b.problem = "no Q: line (does every question start with \"Q:\"?)"
+		case !b.seen[1]:
+			b.problem = "no A: line (is a blank line missing between questions?)"
+		default:
+			// The question mark is added when the question is asked.
+			text := strings.TrimSuffix(strings.Join(b.fields[0], " "), "?")
+			records = append(records, []string{text, strings.Join(b.fields[1], " ")})
+			return nil
+		}
+		rowErr := rowError{Line: b.start, Reason: b.problem, Content: abbreviate(b.first, maxRowContent)}
+		if strict {
+			return rowErr
+		}
+		skipped = append(skipped, rowErr)
+		return nil
+	}
+
+	// started is set once the first question begins, after which no line
+	// is a comment.
+	started := false
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if !started && strings.HasPrefix(line, string(commentChar)) {
+			continue
+		}
+		if line == "" {
+			if err := finish(); err != nil {
+				return nil, nil, nil, err
+			}
+			continue
+		}
+		if block == nil {
+			block = &qaBlock{start: n, first: line, last: -1}
+			started = true
+		}
+
+		field := -1
+		if len(line) >= 2 && line[1] == ':' {
+			field = strings.IndexByte("QA", line[0]&^0x20)
+		}
+		switch {
+		case field >= 0:
+			if block.seen[field] && block.problem == "" {
+				block.problem = fmt.Sprintf("more than one %c: line (is a blank line missing between questions?)", "QA"[field])
+			}
+			block.seen[field] = true
+			block.fields[field] = append(block.fields[field], strings.TrimSpace(line[2:]))
+			block.last = field
+		case block.last >= 0:
+			block.fields[block.last] = append(block.fields[block.last], line)
+		case block.problem == "":
+			block.problem = "text before the Q: line (does every question start with \"Q:\"?)"
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading file: %w", err)
+	}
+	if err := finish(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return records, []string{"question", "answer"}, skipped, nil
+}
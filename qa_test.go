@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadQA(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		records [][]string
+		skipped []int
+	}{
+		{
+			name:    "blocks",
+			content: "Q: What is the capital of France?\nA: Paris\n\nq: 2+2\na: 4\n",
+			records: [][]string{{"What is the capital of France", "Paris"}, {"2+2", "4"}},
+		},
+		{
+			name:    "wrapped lines",
+			content: "Q: Which river flows\nthrough Vienna?\nA: The\nDanube\n",
+			records: [][]string{{"Which river flows through Vienna", "The Danube"}},
+		},
+		{
+			name:    "settings and comments before the first question",
+			content: "# title: Capitals\n# a comment\n\nQ: Capital of Italy\nA: Rome\n",
+			records: [][]string{{"Capital of Italy", "Rome"}},
+		},
+		{
+			name:    "comment character after the first question",
+			content: "Q: Capital of Italy\nA: Rome\n\nQ: Which\n#1 hit was by ABBA?\nA: Waterloo\n",
+			records: [][]string{{"Capital of Italy", "Rome"}, {"Which #1 hit was by ABBA", "Waterloo"}},
+		},
+		{
+			name:    "malformed blocks",
+			content: "Q: No answer\n\nA: No question\n\nQ: One\nA: 1\nQ: Two\nA: 2\n\nQ: Capital of Italy\nA: Rome\n",
+			records: [][]string{{"Capital of Italy", "Rome"}},
+			skipped: []int{1, 3, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "quiz.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			records, headers, skipped, err := readQA(path, false)
+			if err != nil {
+				t.Fatalf("readQA: %v", err)
+			}
+			if !slices.Equal(headers, []string{"question", "answer"}) {
+				t.Errorf("headers = %q, want question, answer", headers)
+			}
+			if !slices.EqualFunc(records, tt.records, slices.Equal) {
+				t.Errorf("records = %q, want %q", records, tt.records)
+			}
+			var lines []int
+			for _, s := range skipped {
+				lines = append(lines, s.Line)
+			}
+			if !slices.Equal(lines, tt.skipped) {
+				t.Errorf("skipped lines = %v, want %v", lines, tt.skipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// errQuit is returned by ask when the user ends the quiz with /quit.
+var errQuit = errors.New("quiz quit")
+
+// confirm asks a yes or no question and reports whether the reply was yes.
+// An error reading the reply counts as no.
+func (e *engine) confirm(prompt string) bool {
+	fmt.Fprintf(e.out, "%s [y/N] ", prompt)
+	reply, err := e.readLine(time.Time{}, 0)
+	if e.opts.Proctor {
+		// The reply is not echoed, so end its line.
+		fmt.Fprintln(e.out)
+	}
+	return err == nil && strings.EqualFold(strings.TrimSpace(reply), "y")
+}
+
+// finishQuit ends a session the user quit with /quit after answering some of
+// the questions, asking whether to save it. The answer is kept in discard.
+func (e *engine) finishQuit(answered, total int) {
+	fmt.Fprintf(e.out, "\nQuit after %d of %d questions.\n", answered, total)
+	e.discard = !e.confirm("Save this session (history and results)?")
+}
+
+// scoredTotal returns the number of questions a score is out of: all total
+// of them, or only those answered if the user quit with /quit, so the
+// questions never asked do not count as wrong.
+func (e *engine) scoredTotal(total int, answered []response) int {
+	if e.quit {
+		return len(answered)
+	}
+	return total
+}
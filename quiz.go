@@ -0,0 +1,658 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// question is a single quiz item read from a quiz file.
+type question struct {
+	// Number is the question's position in the file, counting from 1.
+	Number   int
+	Text     string
+	Answer   string
+	Category string
+	// Section groups questions that are shuffled only among themselves.
+	Section string
+	// Romanization is an optional Latin-script spelling of Answer,
+	// e.g. "Tōkyō" for "東京" or "Běijīng" for "北京".
+	Romanization string
+	// Choices, when set, makes this a multiple-choice question. One of them is the Answer.
+	Choices []string
+	// Difficulty is the share of wrong answers measured by the calibrate
+	// subcommand, from 0 to 1. It is only meaningful if Calibrated is set.
+	Difficulty float64
+	Calibrated bool
+	// Tolerance, when set, gives full or partial credit to numeric answers
+	// close to the Answer (see parseTolerance).
+	Tolerance []toleranceBand
+	// Sentence is an optional example sentence using the Answer, spoken in
+	// spelling tests to tell apart words that sound alike.
+	Sentence string
+	// Reversed marks a question asked the other way round with -direction,
+	// so Text holds the answer in the file and Answer the question.
+	Reversed bool
+	// Quiz is the path of the quiz file the question came from, set in
+	// sessions mixing several quizzes.
+	Quiz string
+	// Stage is stageWarmUp or stageCoolDown for questions that are not graded.
+	Stage string
+	// Revisions is the question's own policy for revising its answer (see
+	// revisionsFree and its siblings); blank uses the quiz's.
+	Revisions string
+	// Hint is shown on asking for it with /hint, if set.
+	Hint string
+	// Notes are the author's notes on the question, such as where it comes
+	// from or what a review said about it. They are never shown to those
+	// taking the quiz, only by the subcommands for reviewing quizzes, and
+	// are left out of the questions sent to the players of hosted games.
+	Notes string `json:"-"`
+}
+
+// choiceSeparator separates the options in the choices column, e.g. "Paris|Lyon|Nice".
+const choiceSeparator = "|"
+
+// maxRowContent is the number of bytes of a malformed row shown in diagnostics.
+const maxRowContent = 80
+
+// maxListedRows is the number of skipped rows listed individually in a warning.
+const maxListedRows = 10
+
+// rowError describes a malformed row in a CSV file.
+type rowError struct {
+	// Line is the line number the row starts on.
+	Line int
+	// Reason says what is wrong with the row and how it can likely be fixed.
+	Reason string
+	// Content is the start of the row as it appears in the file.
+	Content string
+}
+
+func (e rowError) Error() string {
+	return fmt.Sprintf("line %d: %s: %q", e.Line, e.Reason, e.Content)
+}
+
+// newRowError builds a diagnostic for a row the CSV reader rejected.
+//
+// Parameters:
+//   - parseErr: the error returned by the CSV reader.
+//   - record: the fields returned with the error, if any.
+//   - expected: the number of fields every row should have.
+//   - content: the raw text of the row.
+func newRowError(parseErr *csv.ParseError, record []string, expected int, content string) rowError {
+	var reason string
+	switch {
+	case errors.Is(parseErr.Err, csv.ErrFieldCount):
+		reason = fmt.Sprintf("wrong number of fields: expected %d, found %d", expected, len(record))
+		if len(record) > expected {
+			reason += " (is there an unquoted comma in a field?)"
+		}
+	case errors.Is(parseErr.Err, csv.ErrBareQuote):
+		reason = fmt.Sprintf("unescaped quote in an unquoted field at column %d "+
+			"(wrap the field in double quotes and write quotes inside it as \"\")", parseErr.Column)
+	case errors.Is(parseErr.Err, csv.ErrQuote) && parseErr.Line > parseErr.StartLine:
+		reason = fmt.Sprintf("quoted field is never closed and runs on to line %d "+
+			"(is a closing quote missing, or a quote inside it not doubled?)", parseErr.Line)
+	case errors.Is(parseErr.Err, csv.ErrQuote):
+		reason = fmt.Sprintf("unescaped quote inside a quoted field at column %d "+
+			"(write quotes inside quoted fields as \"\")", parseErr.Column)
+	default:
+		reason = parseErr.Err.Error()
+	}
+
+	return rowError{Line: parseErr.StartLine, Reason: reason, Content: content}
+}
+
+// rowContent returns the first line of the bytes between start and end,
+// shortened to maxRowContent bytes.
+func rowContent(src io.ReaderAt, start, end int64) string {
+	buf := make([]byte, min(end-start, maxRowContent))
+	n, _ := src.ReadAt(buf, start)
+	line, _, _ := strings.Cut(string(buf[:n]), "\n")
+	return strings.TrimSuffix(line, "\r")
+}
+
+// readHeader reads the header row of a CSV quiz file, skipping the comment
+// lines before it, which may hold the quiz's settings (see readQuizSettings).
+// The rows after it are read verbatim, so a question may start with
+// commentChar; a stray comment line among them is a malformed row.
+func readHeader(reader *csv.Reader) ([]string, error) {
+	reader.Comment = commentChar
+	defer func() { reader.Comment = 0 }()
+	return reader.Read()
+}
+
+// readRows reads the remaining rows of a CSV file, passing each well-formed row to fn.
+//
+// Parameters:
+//   - reader: the CSV reader, positioned after the header row.
+//   - src: the file being read, used to quote malformed rows in diagnostics.
+//   - strict: whether a malformed row stops reading with an error instead of being skipped.
+//   - fn: called with the byte offset where each row starts and its fields.
+//
+// Returns:
+//   - []rowError: the malformed rows that were skipped, in file order.
+//   - error: the first malformed row in strict mode, or an error if the file cannot be read.
+func readRows(reader *csv.Reader, src io.ReaderAt, strict bool, fn func(offset int64, record []string)) ([]rowError, error) {
+	var skipped []rowError
+	for {
+		offset := reader.InputOffset()
+		record, err := reader.Read()
+		if err == io.EOF {
+			return skipped, nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			content := rowContent(src, offset, reader.InputOffset())
+			rowErr := newRowError(parseErr, record, reader.FieldsPerRecord, content)
+			if strict {
+				return nil, rowErr
+			}
+			skipped = append(skipped, rowErr)
+			if reader.InputOffset() == offset {
+				// The reader cannot make progress past this row.
+				return skipped, nil
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fn(offset, record)
+	}
+}
+
+// formatSkipped describes skipped rows for a warning, listing the line,
+// likely cause and content of each of the first maxListedRows rows.
+func formatSkipped(skipped []rowError) string {
+	noun := "rows"
+	if len(skipped) == 1 {
+		noun = "row"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "skipped %d malformed %s:", len(skipped), noun)
+	for _, s := range skipped[:min(len(skipped), maxListedRows)] {
+		fmt.Fprintf(&b, "\n  line %d: %s\n    %s", s.Line, s.Reason, s.Content)
+	}
+	if len(skipped) > maxListedRows {
+		fmt.Fprintf(&b, "\n  ... and %d more", len(skipped)-maxListedRows)
+	}
+	return b.String()
+}
+
+// loadOptions controls how a quiz file is read.
+type loadOptions struct {
+	// Lang selects the question_<lang> and answer_<lang> columns.
+	Lang string
+	// Shuffle and Limit select which questions are asked.
+	Shuffle bool
+	Limit   int
+	// StrictParse aborts on malformed rows instead of skipping them.
+	StrictParse bool
+	// NoHeader treats the first row as a question.
+	NoHeader bool
+	// Indexed reads only the selected rows through an on-disk index.
+	Indexed bool
+	// Sheet and Columns select the worksheet and columns read from an .xlsx workbook.
+	Sheet   string
+	Columns string
+}
+
+// warnFunc reports a warning about a quiz file, with attributes as for
+// slog.Warn, which is usually the one passed; ignoreWarnings drops them.
+type warnFunc func(msg string, args ...any)
+
+// ignoreWarnings is the warnFunc of loads that report nothing, such as of
+// the quizzes listed to pick from.
+func ignoreWarnings(string, ...any) {}
+
+// loadQuiz reads the questions of a quiz file: CSV, an .xlsx workbook, or
+// plain text in the Q:/A: format (see readQA).
+//
+// Parameters:
+//   - filePath: the path to the quiz file.
+//   - opts: how to read the file. Shuffle and Limit only take effect here in
+//     indexed mode; otherwise the caller applies them with selectQuestions.
+//   - warn: reports skipped rows and a suspected missing header row, with
+//     the file as the "quiz" attribute.
+//
+// Returns:
+//   - []question: the questions in file order, or the sampled ones in indexed mode.
+//   - []string: the header row.
+//   - error: an error if the file cannot be read, or a parseError if it is
+//     malformed or has no columns for opts.Lang.
+func loadQuiz(filePath string, opts loadOptions, warn warnFunc) ([]question, []string, error) {
+	var records [][]string
+	var headers []string
+	var skipped []rowError
+	var err error
+	switch {
+	case isWorkbook(filePath):
+		if opts.Indexed {
+			return nil, nil, fmt.Errorf("-indexed cannot be used with .xlsx files")
+		}
+		records, headers, skipped, err = readXLSX(filePath, opts.Sheet, opts.Columns, opts.StrictParse)
+	case opts.Sheet != "" || opts.Columns != "":
+		return nil, nil, fmt.Errorf("-sheet and -columns only apply to .xlsx files")
+	case isTextQuiz(filePath):
+		if opts.Indexed {
+			return nil, nil, fmt.Errorf("-indexed cannot be used with .txt files")
+		}
+		records, headers, skipped, err = readQA(filePath, opts.StrictParse)
+	case opts.Indexed:
+		records, headers, skipped, err = readIndexedCSV(filePath, opts.Limit, opts.Shuffle, opts.StrictParse)
+	default:
+		records, headers, skipped, err = readCachedCSV(filePath, opts.StrictParse)
+	}
+	if err != nil {
+		return nil, nil, asParseError(filePath, err)
+	}
+
+	questions, headers, err := buildQuiz(records, headers, skipped, opts, func(msg string, args ...any) {
+		warn(msg, append(args, "quiz", filePath)...)
+	})
+	return questions, headers, asParseError(filePath, err)
+}
+
+// maxConcurrentLoads bounds the number of quiz files loadQuizzes reads at once.
+const maxConcurrentLoads = 8
+
+// loadedQuiz is a quiz file read by loadQuizzes.
+type loadedQuiz struct {
+	questions []question
+	headers   []string
+}
+
+// loadQuizzes reads several quiz files at once, as loadQuiz does each, at
+// most maxConcurrentLoads at a time, so that merging a large number of them
+// does not wait on each in turn. Every file is read even if some fail, so
+// that all their errors are reported together.
+//
+// Parameters:
+//   - ctx: the context; files not yet being read are given up once it is done.
+//   - paths: the quiz files.
+//   - opts: how to read the files.
+//   - warn: reports skipped rows, file by file in the order given.
+//
+// Returns:
+//   - []loadedQuiz: the quiz read from each path, in the same order.
+//   - error: the errors of the files that could not be read, each naming its
+//     file, joined with errors.Join.
+func loadQuizzes(ctx context.Context, paths []string, opts loadOptions, warn warnFunc) ([]loadedQuiz, error) {
+	quizzes := make([]loadedQuiz, len(paths))
+	errs := make([]error, len(paths))
+	// Warnings are kept apart so those of one file are not mixed with another's.
+	type warning struct {
+		msg  string
+		args []any
+	}
+	warnings := make([][]warning, len(paths))
+	slots := make(chan struct{}, maxConcurrentLoads)
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				errs[i] = fmt.Errorf("error reading %s: %w", displayPath(path), ctx.Err())
+				return
+			}
+			questions, headers, err := loadQuiz(path, opts, func(msg string, args ...any) {
+				warnings[i] = append(warnings[i], warning{msg, args})
+			})
+			if err != nil {
+				errs[i] = fmt.Errorf("error reading %s: %w", displayPath(path), err)
+				return
+			}
+			quizzes[i] = loadedQuiz{questions: questions, headers: headers}
+		}()
+	}
+	wg.Wait()
+
+	for _, file := range warnings {
+		for _, w := range file {
+			warn(w.msg, w.args...)
+		}
+	}
+	return quizzes, errors.Join(errs...)
+}
+
+// readQuizRecords reads the rows of a quiz file in any format, in full and
+// without the cache, for commands that work on the file rather than take the quiz.
+//
+// Returns:
+//   - [][]string: the rows of the file, excluding the header row.
+//   - []string: the header row.
+//   - []rowError: the malformed rows that were skipped.
+//   - error: an error if the file cannot be read.
+func readQuizRecords(filePath string) ([][]string, []string, []rowError, error) {
+	switch {
+	case isWorkbook(filePath):
+		return readXLSX(filePath, "", "", false)
+	case isTextQuiz(filePath):
+		return readQA(filePath, false)
+	default:
+		return readCSV(filePath, false)
+	}
+}
+
+// buildQuiz turns the rows read from a quiz file into questions.
+//
+// It reports skipped rows, applies opts.NoHeader or warns about a suspected
+// missing header row, and picks the columns for opts.Lang.
+//
+// Parameters:
+//   - records: the rows after the first one.
+//   - headers: the first row.
+//   - skipped: the malformed rows left out of records.
+//   - opts: how the file is read.
+//   - warn: reports skipped rows and a suspected missing header row.
+//
+// Returns:
+//   - []question: the questions in file order.
+//   - []string: the header row.
+//   - error: an error if there are no columns for opts.Lang.
+func buildQuiz(records [][]string, headers []string, skipped []rowError, opts loadOptions, warn warnFunc) ([]question, []string, error) {
+	if len(skipped) > 0 {
+		warn(formatSkipped(skipped))
+	}
+
+	if opts.NoHeader {
+		records = append([][]string{headers}, records...)
+		headers = defaultHeaders(len(headers))
+	} else if looksHeaderless(headers, records) {
+		warn(fmt.Sprintf("the first row %q looks like a question, but is used as the header "+
+			"and will not be asked; use -no-header if the file has no header row", strings.Join(headers, ",")))
+	}
+
+	questions, err := parseQuestions(records, headers, opts.Lang)
+	if err != nil {
+		return nil, nil, err
+	}
+	return questions, headers, nil
+}
+
+// knownColumns are the column names go-quiz gives a meaning to.
+// Columns named question_<lang> and answer_<lang> are recognised as well.
+var knownColumns = []string{"question", "answer", "category", "romanization", "choices", "difficulty", "avg_seconds", "tolerance", "section", "sentence", "revisions", "hint", "notes"}
+
+// looksHeaderless reports whether the first row of a file is probably a
+// question rather than a header row.
+//
+// A row containing a known column name is always a header. Otherwise the
+// row is taken to be a question when it ends in a question mark, or when its
+// answer is a number and so are the answers of most other rows.
+//
+// Parameters:
+//   - headers: the first row of the file.
+//   - records: the remaining rows.
+func looksHeaderless(headers []string, records [][]string) bool {
+	for _, h := range headers {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if slices.Contains(knownColumns, h) || strings.HasPrefix(h, "question_") || strings.HasPrefix(h, "answer_") {
+			return false
+		}
+	}
+
+	if len(headers) < 2 {
+		return false
+	}
+	if strings.HasSuffix(strings.TrimSpace(headers[0]), "?") {
+		return true
+	}
+
+	if !isNumber(headers[1]) || len(records) == 0 {
+		return false
+	}
+	numeric := 0
+	for _, r := range records {
+		if len(r) > 1 && isNumber(r[1]) {
+			numeric++
+		}
+	}
+	return numeric*2 > len(records)
+}
+
+// isNumber reports whether s is a decimal number.
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil
+}
+
+// defaultHeaders returns header names for a file without a header row:
+// "question" and "answer" followed by "column3", "column4" and so on.
+func defaultHeaders(n int) []string {
+	headers := make([]string, n)
+	for i := range headers {
+		switch i {
+		case 0:
+			headers[i] = "question"
+		case 1:
+			headers[i] = "answer"
+		default:
+			headers[i] = fmt.Sprintf("column%d", i+1)
+		}
+	}
+	return headers
+}
+
+// parseQuestions converts CSV records into questions.
+//
+// Parameters:
+//   - records: the rows of the quiz file, excluding the header row.
+//   - headers: the header row, used to locate optional columns.
+//   - lang: the language code selected with -lang; empty selects the default columns.
+//
+// Returns:
+//   - []question: the questions in file order.
+//   - error: an error if the requested language is not present in the quiz,
+//     or a tolerance or revision policy is malformed.
+func parseQuestions(records [][]string, headers []string, lang string) ([]question, error) {
+	questionCol, answerCol, err := languageColumns(headers, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryCol := columnIndex(headers, "category")
+	romanizationCol := columnIndex(headers, "romanization")
+	choicesCol := columnIndex(headers, "choices")
+	difficultyCol := columnIndex(headers, "difficulty")
+	toleranceCol := columnIndex(headers, "tolerance")
+	sectionCol := columnIndex(headers, "section")
+	sentenceCol := columnIndex(headers, "sentence")
+	revisionsCol := columnIndex(headers, "revisions")
+	hintCol := columnIndex(headers, "hint")
+	notesCol := columnIndex(headers, "notes")
+
+	questions := make([]question, 0, len(records))
+	for i, row := range records {
+		q := question{
+			Number: i + 1,
+			Text:   row[questionCol],
+			Answer: row[answerCol],
+		}
+		if categoryCol >= 0 {
+			q.Category = row[categoryCol]
+		}
+		if sectionCol >= 0 {
+			q.Section = strings.TrimSpace(row[sectionCol])
+		}
+		if sentenceCol >= 0 {
+			q.Sentence = strings.TrimSpace(row[sentenceCol])
+		}
+		if revisionsCol >= 0 {
+			q.Revisions = strings.ToLower(strings.TrimSpace(row[revisionsCol]))
+			if q.Revisions != "" {
+				if err := validateRevisions(q.Revisions); err != nil {
+					return nil, fmt.Errorf("question %q: %w", q.Text, err)
+				}
+			}
+		}
+		if hintCol >= 0 {
+			q.Hint = strings.TrimSpace(row[hintCol])
+		}
+		if notesCol >= 0 {
+			q.Notes = strings.TrimSpace(row[notesCol])
+		}
+		if romanizationCol >= 0 {
+			q.Romanization = row[romanizationCol]
+		}
+		if choicesCol >= 0 && strings.TrimSpace(row[choicesCol]) != "" {
+			for _, choice := range strings.Split(row[choicesCol], choiceSeparator) {
+				q.Choices = append(q.Choices, strings.TrimSpace(choice))
+			}
+		}
+		if difficultyCol >= 0 {
+			// Blank or malformed difficulties leave the question uncalibrated.
+			if d, err := strconv.ParseFloat(strings.TrimSpace(row[difficultyCol]), 64); err == nil && d >= 0 && d <= 1 {
+				q.Difficulty, q.Calibrated = d, true
+			}
+		}
+		if toleranceCol >= 0 {
+			if q.Tolerance, err = parseTolerance(row[toleranceCol]); err != nil {
+				return nil, fmt.Errorf("question %q: %w", q.Text, err)
+			}
+		}
+		questions = append(questions, q)
+	}
+	return questions, nil
+}
+
+// selectQuestions applies the -shuffle and -limit options to the questions.
+//
+// Parameters:
+//   - questions: the questions in file order; shuffled in place when shuffle is set.
+//   - shuffle: whether to randomise the order of the questions.
+//   - constraints: the limits the quiz puts on shuffling, if any.
+//   - limit: the maximum number of questions to keep; 0 or less keeps all of them.
+//
+// Returns:
+//   - []question: the questions to ask, in the order to ask them.
+func selectQuestions(questions []question, shuffle bool, constraints shuffleConstraints, limit int) []question {
+	if shuffle {
+		shuffleQuestions(questions, constraints)
+	}
+	if limit > 0 && limit < len(questions) {
+		questions = questions[:limit]
+	}
+	return questions
+}
+
+// isCorrect reports whether answer is an accepted answer to the question.
+//
+// Directionality control characters are ignored on both sides, so answers
+// typed in right-to-left scripts compare equal to the same visible text.
+// If the question has a romanization, it is also accepted regardless of
+// case, tone marks, tone numbers, and spacing.
+func (q question) isCorrect(answer string) bool {
+	if stripBidiControls(answer) == stripBidiControls(q.Answer) {
+		return true
+	}
+	if q.Romanization != "" && foldRomanization(answer) == foldRomanization(q.Romanization) {
+		return true
+	}
+	return false
+}
+
+// credit returns the share of a point the answer earns: 1 for an accepted
+// answer, and for a numeric question with a tolerance, the credit of the
+// closest band the answer falls in.
+func (q question) credit(answer string) float64 {
+	if q.isCorrect(answer) {
+		return 1
+	}
+	if len(q.Tolerance) > 0 {
+		return q.toleranceCredit(answer)
+	}
+	return 0
+}
+
+// choiceAnswer turns a multiple-choice answer given as a letter, such as "b",
+// into the text of that choice. Any other answer is returned unchanged, so
+// the choice can also be typed out in full.
+func (q question) choiceAnswer(answer string) string {
+	letter := strings.ToLower(strings.TrimSpace(answer))
+	if len(letter) == 1 && letter[0] >= 'a' && int(letter[0]-'a') < len(q.Choices) {
+		return q.Choices[letter[0]-'a']
+	}
+	return answer
+}
+
+// columnIndex returns the position of the named column within the headers.
+//
+// The comparison is case-insensitive and ignores surrounding whitespace.
+// It returns -1 if the column is not present.
+func columnIndex(headers []string, name string) int {
+	for i, h := range headers {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// languageColumns returns the question and answer column positions for a language.
+//
+// Parameters:
+//   - headers: the header row of the quiz file.
+//   - lang: the language code selected with -lang; empty selects the default columns.
+//
+// Returns:
+//   - int: the position of the question column.
+//   - int: the position of the answer column.
+//   - error: an error if the quiz has no columns for the requested language.
+func languageColumns(headers []string, lang string) (int, int, error) {
+	if lang == "" {
+		if len(headers) < 2 {
+			return 0, 0, fmt.Errorf("quiz needs at least a question and an answer column, found %d column(s)", len(headers))
+		}
+		return 0, 1, nil
+	}
+
+	questionCol := columnIndex(headers, "question_"+lang)
+	answerCol := columnIndex(headers, "answer_"+lang)
+	if questionCol < 0 || answerCol < 0 {
+		return 0, 0, fmt.Errorf("quiz has no question_%s and answer_%s columns", lang, lang)
+	}
+	return questionCol, answerCol, nil
+}
+
+// romanizationFolds maps accented Latin vowels used by pinyin and Hepburn
+// romanization to their plain forms.
+var romanizationFolds = strings.NewReplacer(
+	"ā", "a", "á", "a", "ǎ", "a", "à", "a", "â", "a",
+	"ē", "e", "é", "e", "ě", "e", "è", "e", "ê", "e",
+	"ī", "i", "í", "i", "ǐ", "i", "ì", "i", "î", "i",
+	"ō", "o", "ó", "o", "ǒ", "o", "ò", "o", "ô", "o",
+	"ū", "u", "ú", "u", "ǔ", "u", "ù", "u", "û", "u",
+	"ǖ", "u", "ǘ", "u", "ǚ", "u", "ǜ", "u", "ü", "u",
+)
+
+// foldRomanization reduces a romanized answer to a canonical comparison form.
+//
+// It lowercases the text, removes tone marks and macrons, and drops tone
+// numbers, spaces, apostrophes and hyphens, so "Běijīng", "bei3 jing1" and
+// "Beijing" all fold to "beijing".
+func foldRomanization(s string) string {
+	s = romanizationFolds.Replace(strings.ToLower(stripBidiControls(s)))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || unicode.IsSpace(r) || r == '\'' || r == '’' || r == '-' {
+			return -1
+		}
+		return r
+	}, s)
+}
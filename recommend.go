@@ -0,0 +1,237 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+	"time"
+)
+
+// recommendHalfLife is how long it takes an answer to count half as much
+// towards a recommendation, so recent mistakes matter most.
+const recommendHalfLife = 14 * 24 * time.Hour
+
+// minRecommendAttempts is the number of attempts a user must have made at a
+// quiz before recommendations are made.
+const minRecommendAttempts = 3
+
+// drillStats are the recency-weighted answers of a user to a question or a category.
+type drillStats struct {
+	// Answers is the number of answers given.
+	Answers int
+	// Weight and Wrong are the sums of the weights of all answers and of the wrong ones.
+	Weight float64
+	Wrong  float64
+	// LastSeen is when the question or category was last answered.
+	LastSeen time.Time
+}
+
+// errorRate returns the recency-weighted share of wrong answers.
+func (s drillStats) errorRate() float64 {
+	if s.Weight == 0 {
+		return 0
+	}
+	return s.Wrong / s.Weight
+}
+
+// add counts an answer given at t, weighted by its age at now.
+func (s *drillStats) add(correct bool, t, now time.Time) {
+	w := math.Pow(0.5, float64(now.Sub(t))/float64(recommendHalfLife))
+	s.Answers++
+	s.Weight += w
+	if !correct {
+		s.Wrong += w
+	}
+	if t.After(s.LastSeen) {
+		s.LastSeen = t
+	}
+}
+
+// recommendation is a question worth drilling.
+type recommendation struct {
+	Key   masteryKey
+	Stats drillStats
+}
+
+// recommendDrills ranks the questions a user most needs to drill.
+//
+// Parameters:
+//   - entries: the attempts at a quiz, oldest first.
+//   - user: the user to recommend questions to.
+//   - categories: the category of each question, by its text in the quiz file.
+//   - minAnswers: the number of answers to a question needed to judge it.
+//   - now: the time the weights are computed at.
+//
+// Returns:
+//   - []recommendation: the questions answered wrongly at least once, highest
+//     error rate first, and among equals the longest unseen first.
+//   - map[string]drillStats: the stats of each category, if the quiz has categories.
+func recommendDrills(entries []historyEntry, user string, categories map[string]string, minAnswers int, now time.Time) ([]recommendation, map[string]drillStats) {
+	questions := make(map[masteryKey]drillStats)
+	byCategory := make(map[string]drillStats)
+	for _, e := range entries {
+		if e.User != user {
+			continue
+		}
+		for _, outcome := range e.Questions {
+			category, known := categories[outcome.Question]
+			if !known {
+				// The question has since been removed from the quiz.
+				continue
+			}
+			key := masteryKey{Question: outcome.Question, Reversed: outcome.Reversed}
+			s := questions[key]
+			s.add(outcome.Correct, e.Time, now)
+			questions[key] = s
+			if category != "" {
+				c := byCategory[category]
+				c.add(outcome.Correct, e.Time, now)
+				byCategory[category] = c
+			}
+		}
+	}
+
+	var drills []recommendation
+	for key, s := range questions {
+		if s.Answers >= minAnswers && s.Wrong > 0 {
+			drills = append(drills, recommendation{Key: key, Stats: s})
+		}
+	}
+	slices.SortFunc(drills, func(a, b recommendation) int {
+		if c := cmp.Compare(b.Stats.errorRate(), a.Stats.errorRate()); c != 0 {
+			return c
+		}
+		if c := a.Stats.LastSeen.Compare(b.Stats.LastSeen); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Key.Question, b.Key.Question)
+	})
+	return drills, byCategory
+}
+
+// runRecommend implements the "recommend" subcommand.
+//
+// It suggests the categories and questions of a quiz a user should drill
+// next, from their error rates with recent answers weighing more (see
+// recommendHalfLife). With -start it runs a drill session on the suggested
+// questions straight away; like clip, the session is practice and is not
+// recorded in the history.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the quiz or history cannot be read.
+func runRecommend(ctx context.Context, args []string) error {
+	flags := newFlagSet("recommend")
+	opts := registerQuizFlags(flags)
+	top := flags.Int("top", 10, "suggest at most `n` questions")
+	minAnswers := flags.Int("min-answers", 2, "only suggest questions answered at least `n` times")
+	start := flags.Bool("start", false, "drill the suggested questions now")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *top < 1 || *minAnswers < 1 {
+		return usageErrorf("usage: go-quiz recommend [-top n] [-min-answers n] [-start] [quiz flags] quiz.csv")
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	quiz, err := filepath.Abs(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error expanding path: %w", err)
+	}
+	entries, err := loadHistory(ctx, quiz)
+	if err != nil {
+		return err
+	}
+	user := currentUser()
+	attempts := 0
+	for _, e := range entries {
+		if e.User == user {
+			attempts++
+		}
+	}
+	if attempts < minRecommendAttempts {
+		fmt.Fprintf(stdout, "Not enough history yet: recommendations need %d attempts at the quiz and you have made %d.\n",
+			minRecommendAttempts, attempts)
+		return nil
+	}
+
+	questions, headers, err := loadQuiz(quiz, loadOptions{Lang: opts.load.Lang}, slog.Warn)
+	if err != nil {
+		return err
+	}
+	categories := make(map[string]string, len(questions))
+	for _, q := range questions {
+		categories[q.Text] = q.Category
+	}
+	drills, byCategory := recommendDrills(entries, user, categories, *minAnswers, time.Now())
+	if len(drills) > *top {
+		drills = drills[:*top]
+	}
+	if len(drills) == 0 {
+		fmt.Fprintln(stdout, "Nothing to drill: you have answered every question correctly lately.")
+		return nil
+	}
+
+	writeRecommendations(drills, byCategory)
+	if !*start {
+		fmt.Fprintf(stdout, "Run go-quiz recommend -start %s to drill them now.\n", flags.Arg(0))
+		return nil
+	}
+
+	var drill []question
+	for _, d := range drills {
+		i := slices.IndexFunc(questions, func(q question) bool { return q.Text == d.Key.Question })
+		q := questions[i]
+		if d.Key.Reversed {
+			q = q.reversed()
+		}
+		drill = append(drill, q)
+	}
+	fmt.Fprintln(stdout)
+	// Like clip, the drill is practice: no attempt is recorded and no
+	// certificate issued, but reports are saved on the quiz.
+	return runSession(ctx, opts, flags, session{
+		quiz:       quiz,
+		questions:  selectQuestions(drill, opts.load.Shuffle, shuffleConstraints{}, opts.load.Limit),
+		byCategory: columnIndex(headers, "category") >= 0,
+	})
+}
+
+// writeRecommendations prints the categories, weakest first, and the questions to drill.
+func writeRecommendations(drills []recommendation, byCategory map[string]drillStats) {
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	if len(byCategory) > 0 {
+		names := slices.Sorted(maps.Keys(byCategory))
+		slices.SortStableFunc(names, func(a, b string) int {
+			return cmp.Compare(byCategory[b].errorRate(), byCategory[a].errorRate())
+		})
+		fmt.Fprintln(w, "Categories, weakest first:")
+		for _, name := range names {
+			fmt.Fprintf(w, "  %s\t%.0f%% wrong\n", name, 100*byCategory[name].errorRate())
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "Questions to drill:")
+	for _, d := range drills {
+		text := d.Key.Question
+		if d.Key.Reversed {
+			text += " (reverse)"
+		}
+		fmt.Fprintf(w, "  %.0f%% wrong\tlast seen %s\t%s\n", 100*d.Stats.errorRate(),
+			d.Stats.LastSeen.Format("2006-01-02"), abbreviate(text, terminalWidth()-36))
+	}
+	w.Flush()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// castRecorder records terminal output as an asciinema cast (format version 2)
+// while passing it through to the terminal.
+//
+// A cast file is a JSON header line followed by one JSON array per event:
+// the seconds since the start, the event type ("o" for output) and the data.
+type castRecorder struct {
+	mu    sync.Mutex
+	file  *os.File
+	w     *bufio.Writer
+	start time.Time
+	err   error
+}
+
+// castHeader is the first line of a cast file.
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// newCastRecorder creates a cast file at path for a terminal of the given size.
+func newCastRecorder(path string, width, height int) (*castRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating recording: %w", err)
+	}
+
+	r := &castRecorder{file: file, w: bufio.NewWriter(file), start: time.Now()}
+	header := castHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: r.start.Unix(),
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	}
+	if err := json.NewEncoder(r.w).Encode(header); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error writing recording: %w", err)
+	}
+	return r, nil
+}
+
+// record adds an output event with the given text, converting line endings
+// to the carriage return and line feed a terminal in raw mode expects.
+func (r *castRecorder) record(text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil || text == "" {
+		return
+	}
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+	event := []any{time.Since(r.start).Seconds(), "o", text}
+	r.err = json.NewEncoder(r.w).Encode(event)
+}
+
+// tee returns a writer that writes to w and records everything written.
+func (r *castRecorder) tee(w io.Writer) io.Writer {
+	return castWriter{w: w, rec: r}
+}
+
+// Close flushes and closes the cast file, reporting any error met while recording.
+func (r *castRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.w.Flush(); err != nil && r.err == nil {
+		r.err = err
+	}
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	if r.err != nil {
+		return fmt.Errorf("error writing recording: %w", r.err)
+	}
+	return nil
+}
+
+// castWriter passes writes through to w and records them.
+type castWriter struct {
+	w   io.Writer
+	rec *castRecorder
+}
+
+func (cw castWriter) Write(p []byte) (int, error) {
+	cw.rec.record(string(p))
+	return cw.w.Write(p)
+}
+
+// castSource records each line read from src as it would have been echoed by
+// the terminal. Input is read a line at a time, so a typed line appears in the
+// recording all at once when Enter is pressed.
+type castSource struct {
+	src lineSource
+	rec *castRecorder
+	// hidden, when it points to true, leaves input out of the recording, as it
+	// is not echoed in proctor mode. It is a pointer because a quiz's settings
+	// can still turn proctor mode on once the quiz file is chosen.
+	hidden *bool
+}
+
+func (cs castSource) readLine(ctx context.Context, c clock, timeout time.Duration) (string, error) {
+	line, err := cs.src.readLine(ctx, c, timeout)
+	if err == nil && !*cs.hidden {
+		cs.rec.record(line + "\n")
+	}
+	return line, err
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// registryEnv names the environment variable holding the default registry location.
+const registryEnv = "GO_QUIZ_REGISTRY"
+
+// registryTimeout bounds every request made to a registry.
+const registryTimeout = 30 * time.Second
+
+// registryIndex is the JSON document listing the quizzes in a registry.
+type registryIndex struct {
+	Quizzes []registryEntry `json:"quizzes"`
+}
+
+// registryEntry describes one quiz in a registry.
+//
+// URL may be relative to the index. SHA256 is the hex-encoded checksum of the
+// quiz file, checked on download.
+type registryEntry struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Questions   int      `json:"questions"`
+	Categories  []string `json:"categories,omitempty"`
+	URL         string   `json:"url"`
+	SHA256      string   `json:"sha256"`
+}
+
+// registryFlag registers the -registry flag shared by the registry subcommands.
+func registryFlag(flags *flag.FlagSet) *string {
+	return flags.String("registry", os.Getenv(registryEnv),
+		"registry index `location`: an http(s) URL, file:// URL or path (default $"+registryEnv+")")
+}
+
+// openRegistry opens a location in a registry, which is either an http(s) URL
+// or a local file given as a file:// URL or a path, as with a registry kept
+// in a git checkout. Fetching a URL gives up if ctx is done first.
+func openRegistry(ctx context.Context, location string) (io.ReadCloser, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching %s: %w", location, err)
+		}
+		client := http.Client{Timeout: registryTimeout}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching %s: %w", location, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("error fetching %s: %s", location, resp.Status)
+		}
+		return resp.Body, nil
+	}
+
+	file, err := os.Open(strings.TrimPrefix(location, "file://"))
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", location, err)
+	}
+	return file, nil
+}
+
+// resolveRegistryURL resolves a quiz location listed in an index against the index's own location.
+func resolveRegistryURL(index, ref string) (string, error) {
+	if strings.Contains(index, "://") {
+		base, err := url.Parse(index)
+		if err != nil {
+			return "", fmt.Errorf("error parsing registry location: %w", err)
+		}
+		target, err := url.Parse(ref)
+		if err != nil {
+			return "", fmt.Errorf("error parsing quiz location: %w", err)
+		}
+		return base.ResolveReference(target).String(), nil
+	}
+
+	if strings.Contains(ref, "://") || filepath.IsAbs(ref) {
+		return ref, nil
+	}
+	return filepath.Join(filepath.Dir(index), ref), nil
+}
+
+// loadRegistryIndex fetches and decodes a registry index.
+func loadRegistryIndex(ctx context.Context, location string) (registryIndex, error) {
+	var index registryIndex
+	if location == "" {
+		return index, fmt.Errorf("no registry configured: use -registry or set $%s", registryEnv)
+	}
+
+	body, err := openRegistry(ctx, location)
+	if err != nil {
+		return index, err
+	}
+	defer body.Close()
+
+	if err := json.NewDecoder(body).Decode(&index); err != nil {
+		return index, fmt.Errorf("error decoding registry index: %w", err)
+	}
+	return index, nil
+}
+
+// runBrowse implements the "browse" subcommand.
+//
+// It lists the quizzes in a registry, optionally only those whose name, title,
+// description or categories contain a search term.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the index cannot be read.
+func runBrowse(ctx context.Context, args []string) error {
+	flags := newFlagSet("browse")
+	registry := registryFlag(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 {
+		return usageErrorf("usage: go-quiz browse [-registry location] [search]")
+	}
+	search := strings.ToLower(flags.Arg(0))
+
+	index, err := loadRegistryIndex(ctx, *registry)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tQUESTIONS\tTITLE")
+	found := 0
+	for _, entry := range index.Quizzes {
+		text := strings.ToLower(strings.Join(append([]string{entry.Name, entry.Title, entry.Description}, entry.Categories...), " "))
+		if !strings.Contains(text, search) {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", entry.Name, entry.Questions, entry.Title)
+		found++
+	}
+	w.Flush()
+
+	if found == 0 {
+		fmt.Println("No quizzes found.")
+	}
+	return nil
+}
+
+// runGet implements the "get" subcommand.
+//
+// It downloads a quiz from a registry, verifies its checksum, and saves it as
+// <name>.csv in the current directory or at the path given with -o. Nothing
+// is written if the checksum does not match.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, the quiz is not in the
+//     registry, or the download fails or does not match its checksum.
+func runGet(ctx context.Context, args []string) error {
+	flags := newFlagSet("get")
+	registry := registryFlag(flags)
+	output := flags.String("o", "", "save the quiz to `file` instead of <name>.csv")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageErrorf("usage: go-quiz get [-registry location] [-o file] name")
+	}
+	name := flags.Arg(0)
+
+	index, err := loadRegistryIndex(ctx, *registry)
+	if err != nil {
+		return err
+	}
+
+	var entry *registryEntry
+	for i := range index.Quizzes {
+		if index.Quizzes[i].Name == name {
+			entry = &index.Quizzes[i]
+			break
+		}
+	}
+	if entry == nil {
+		return fmt.Errorf("quiz %q not found in registry", name)
+	}
+	if entry.SHA256 == "" {
+		return fmt.Errorf("quiz %q has no checksum in the registry", name)
+	}
+
+	location, err := resolveRegistryURL(*registry, entry.URL)
+	if err != nil {
+		return err
+	}
+
+	path := *output
+	if path == "" {
+		path = filepath.Base(name) + ".csv"
+	}
+
+	if err := download(ctx, location, path, entry.SHA256); err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved %s (%d questions) to %s\n", entry.Name, entry.Questions, path)
+	return nil
+}
+
+// download copies location to path, checking that its SHA-256 checksum is want.
+// The data is written to a temporary file first and only renamed into place once verified.
+func download(ctx context.Context, location, path, want string) error {
+	body, err := openRegistry(ctx, location)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".go-quiz-*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), body); err != nil {
+		return fmt.Errorf("error downloading quiz: %w", err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, want) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", want, got)
+	}
+
+	if err := tmp.Chmod(0o644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error saving file: %w", err)
+	}
+	return nil
+}
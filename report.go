@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// reportsFileName is the file in the state directory holding the questions
+// reported with /report.
+const reportsFileName = "reports.jsonl"
+
+// questionReport is a question flagged with /report as wrong or ambiguous.
+type questionReport struct {
+	// Quiz is the absolute path of the quiz file.
+	Quiz string `json:"quiz"`
+	// Question is the question's text in the file, whichever way it was asked.
+	Question string    `json:"question"`
+	Reason   string    `json:"reason"`
+	User     string    `json:"user,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// saveReports adds the questions reported during a session to the reports
+// in the storage in use (see openStorage).
+//
+// Parameters:
+//   - ctx: the context of the request to a storage server, if one is used.
+//   - filePath: the quiz taken, for reports on questions not marked with their quiz.
+//   - reports: the reports made during the session.
+//
+// Returns:
+//   - error: an error if the reports cannot be written.
+func saveReports(ctx context.Context, filePath string, reports []questionReport) error {
+	if len(reports) == 0 {
+		return nil
+	}
+	quiz, err := filepath.Abs(filePath)
+	if err != nil {
+		return fmt.Errorf("error expanding path: %w", err)
+	}
+	store, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	reports = slices.Clone(reports)
+	for i := range reports {
+		if reports[i].Quiz == "" {
+			reports[i].Quiz = quiz
+		}
+		reports[i].User = currentUser()
+	}
+	return store.appendReports(ctx, reports)
+}
+
+// appendReports adds reports at the end of the reports file, creating the
+// state directory and file if needed.
+func (fileStorage) appendReports(_ context.Context, reports []questionReport) error {
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating state directory: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, reportsFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening reports: %w", err)
+	}
+	defer file.Close()
+
+	for _, r := range reports {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("error encoding report: %w", err)
+		}
+		if _, err := file.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("error writing reports: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadReports reads the reports on a quiz, or on every quiz if quiz is
+// empty, from the storage in use.
+func loadReports(ctx context.Context, quiz string) ([]questionReport, error) {
+	store, err := openStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.loadReports(ctx, quiz)
+}
+
+// loadReports reads the reports on a quiz, or on every quiz if quiz is empty,
+// from the reports file. A missing file means there are none.
+func (fileStorage) loadReports(_ context.Context, quiz string) ([]questionReport, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filepath.Join(dir, reportsFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening reports: %w", err)
+	}
+	defer file.Close()
+
+	var reports []questionReport
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r questionReport
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("error parsing reports: %w", err)
+		}
+		if quiz == "" || r.Quiz == quiz {
+			reports = append(reports, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading reports: %w", err)
+	}
+	return reports, nil
+}
+
+// reportedQuestion identifies a reported question: the quiz file and the
+// question's text in it.
+type reportedQuestion struct{ Quiz, Question string }
+
+// reportedNotes returns the author's notes on the reported questions that
+// have any, read from their quiz files. Quizzes that cannot be read, such
+// as those moved since, are left out.
+func reportedNotes(reports []questionReport) map[reportedQuestion]string {
+	notes := make(map[reportedQuestion]string)
+	loaded := make(map[string]bool)
+	for _, r := range reports {
+		if loaded[r.Quiz] {
+			continue
+		}
+		loaded[r.Quiz] = true
+		questions, _, err := loadQuiz(r.Quiz, loadOptions{}, ignoreWarnings)
+		if err != nil {
+			continue
+		}
+		for _, q := range questions {
+			if q.Notes != "" {
+				notes[reportedQuestion{r.Quiz, q.Text}] = q.Notes
+			}
+		}
+	}
+	return notes
+}
+
+// writeReports lists the reported questions, most reported first, each with
+// the author's notes on it, if any, and the reasons given.
+func writeReports(w io.Writer, reports []questionReport, notes map[reportedQuestion]string) {
+	byQuestion := make(map[reportedQuestion][]questionReport)
+	var keys []reportedQuestion
+	for _, r := range reports {
+		k := reportedQuestion{r.Quiz, r.Question}
+		if _, ok := byQuestion[k]; !ok {
+			keys = append(keys, k)
+		}
+		byQuestion[k] = append(byQuestion[k], r)
+	}
+	slices.SortStableFunc(keys, func(a, b reportedQuestion) int {
+		return cmp.Compare(len(byQuestion[b]), len(byQuestion[a]))
+	})
+
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s: %s (%d)\n", displayPath(k.Quiz), displayText(k.Question), len(byQuestion[k]))
+		if notes[k] != "" {
+			fmt.Fprintf(w, "  Notes: %s\n", displayText(notes[k]))
+		}
+		for _, r := range byQuestion[k] {
+			fmt.Fprintf(w, "  %s  %s: %s\n", r.Time.Local().Format("2006-01-02"), r.User, displayText(r.Reason))
+		}
+	}
+}
+
+// runReports implements the "reports" subcommand.
+//
+// It lists the questions reported with /report as wrong or ambiguous, on one
+// quiz or on every quiz, so the quiz's author can fix them. A question's
+// notes column, if its quiz has one, is shown with it.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the reports cannot be read.
+func runReports(ctx context.Context, args []string) error {
+	flags := newFlagSet("reports")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 {
+		return usageErrorf("usage: go-quiz reports [quiz.csv]")
+	}
+	quiz := ""
+	if flags.NArg() == 1 {
+		abs, err := filepath.Abs(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("error expanding path: %w", err)
+		}
+		quiz = abs
+	}
+
+	reports, err := loadReports(ctx, quiz)
+	if err != nil {
+		return err
+	}
+	if len(reports) == 0 {
+		fmt.Fprintln(stdout, "No questions have been reported.")
+		return nil
+	}
+	writeReports(stdout, reports, reportedNotes(reports))
+	return nil
+}
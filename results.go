@@ -0,0 +1,141 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// questionResult is the outcome of a single question as written to a results file.
+type questionResult struct {
+	Question string  `json:"question"`
+	Expected string  `json:"expected"`
+	Answer   string  `json:"answer"`
+	Correct  bool    `json:"correct"`
+	Credit   float64 `json:"credit"`
+	Category string  `json:"category,omitempty"`
+	Seconds  float64 `json:"seconds"`
+	// Stage is "warm-up" or "cool-down" for questions that are not graded.
+	Stage string `json:"stage,omitempty"`
+	// Explanation is the reason given for the answer, if any.
+	Explanation string `json:"explanation,omitempty"`
+	// Notes are the notes taken while answering, if any.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// newQuestionResults converts responses into their results-file form.
+func newQuestionResults(responses []response) []questionResult {
+	results := make([]questionResult, 0, len(responses))
+	for _, r := range responses {
+		results = append(results, questionResult{
+			Question:    r.Question.Text,
+			Expected:    r.Question.Answer,
+			Answer:      r.Answer,
+			Correct:     r.correct(),
+			Credit:      r.credit(),
+			Category:    r.Question.Category,
+			Seconds:     r.Duration.Seconds(),
+			Stage:       r.Question.Stage,
+			Explanation: r.Explanation,
+			Notes:       r.Notes,
+		})
+	}
+	return results
+}
+
+// slowestResponses returns up to n responses ordered from slowest to fastest.
+func slowestResponses(responses []response, n int) []response {
+	sorted := slices.Clone(responses)
+	slices.SortStableFunc(sorted, func(a, b response) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	return sorted[:min(n, len(sorted))]
+}
+
+// formatSlowest renders the given responses as an indented list with their timings,
+// shortening questions to fit within width columns.
+func formatSlowest(responses []response, width int) string {
+	var b strings.Builder
+	b.WriteString("Slowest questions:")
+	for _, r := range responses {
+		fmt.Fprintf(&b, "\n  %5.1fs  %s", r.Duration.Seconds(), abbreviate(r.Question.Text, width-10))
+	}
+	return b.String()
+}
+
+// writeResults writes per-question results to a file.
+//
+// The format is chosen from the file extension: ".json" writes a JSON array
+// and ".csv" writes a CSV file with a header row.
+//
+// Parameters:
+//   - path: the file to create or overwrite.
+//   - responses: the responses of the finished quiz.
+//
+// Returns:
+//   - error: an error if the extension is unsupported or the file cannot be written.
+func writeResults(path string, responses []response) error {
+	write, err := resultsEncoder(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating results file: %w", err)
+	}
+	defer file.Close()
+
+	if err := write(file, newQuestionResults(responses)); err != nil {
+		return fmt.Errorf("error writing results: %w", err)
+	}
+	return nil
+}
+
+// resultsEncoder returns the function that writes results in the format matching the file extension.
+func resultsEncoder(path string) (func(io.Writer, []questionResult) error, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return writeResultsJSON, nil
+	case ".csv":
+		return writeResultsCSV, nil
+	default:
+		return nil, fmt.Errorf("unsupported results format %q: use .json or .csv", filepath.Ext(path))
+	}
+}
+
+// writeResultsJSON writes results as an indented JSON array.
+func writeResultsJSON(w io.Writer, results []questionResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+// writeResultsCSV writes results as CSV with a header row.
+func writeResultsCSV(w io.Writer, results []questionResult) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"question", "expected", "answer", "correct", "credit", "category", "seconds", "stage", "explanation", "notes"})
+	for _, r := range results {
+		writer.Write([]string{
+			r.Question,
+			r.Expected,
+			r.Answer,
+			strconv.FormatBool(r.Correct),
+			strconv.FormatFloat(r.Credit, 'f', -1, 64),
+			r.Category,
+			strconv.FormatFloat(r.Seconds, 'f', 2, 64),
+			r.Stage,
+			r.Explanation,
+			strings.Join(r.Notes, "; "),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
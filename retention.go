@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseRetention parses a retention period: a number of days or weeks, such
+// as 90d or 12w, or a Go duration such as 720h.
+func parseRetention(value string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	switch {
+	case strings.HasSuffix(value, "d") || strings.HasSuffix(value, "w"):
+		var n int
+		n, err = strconv.Atoi(value[:len(value)-1])
+		d = time.Duration(n) * 24 * time.Hour
+		if strings.HasSuffix(value, "w") {
+			d *= 7
+		}
+	default:
+		d, err = time.ParseDuration(value)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid retention period %q: use a number of days or weeks, e.g. 90d or 12w", value)
+	}
+	return d, nil
+}
+
+// pruneOptions says which records prune takes away and how.
+type pruneOptions struct {
+	// Before is the time records must be older than to be pruned.
+	Before time.Time `json:"before"`
+	// Anonymize keeps the attempts and reports, without the user's name,
+	// instead of deleting them.
+	Anonymize bool `json:"anonymize,omitempty"`
+	// DryRun counts the records that would be pruned without changing them.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// pruneResult counts the records pruned.
+type pruneResult struct {
+	Attempts int `json:"attempts"`
+	Reports  int `json:"reports"`
+	// Resets counts mastery resets, which are always deleted: they only
+	// hide a user's earlier answers, which are then gone or anonymous.
+	Resets int `json:"resets"`
+}
+
+// pruneRecords applies opts to records, returning those kept and the number
+// pruned. old reports whether a record was made before opts.Before, and
+// anonymize takes the user's name out of one.
+func pruneRecords[T any](records []T, opts pruneOptions, old func(T) bool, anonymize func(*T)) ([]T, int) {
+	kept := make([]T, 0, len(records))
+	pruned := 0
+	for _, r := range records {
+		if !old(r) {
+			kept = append(kept, r)
+			continue
+		}
+		pruned++
+		if opts.Anonymize {
+			anonymize(&r)
+			kept = append(kept, r)
+		}
+	}
+	return kept, pruned
+}
+
+// prune deletes or anonymizes the attempts and reports in the state
+// directory made before opts.Before, and deletes the mastery resets made
+// before then.
+func (s fileStorage) prune(ctx context.Context, opts pruneOptions) (pruneResult, error) {
+	return s.removeRecords(ctx, opts, func(user string, t time.Time) bool {
+		return t.Before(opts.Before) && (user != "" || !opts.Anonymize)
+	})
+}
+
+// removeRecords deletes or, with opts.Anonymize, anonymizes the attempts and
+// reports in the state directory that match, and deletes the mastery resets
+// that match. Each file is replaced whole, so records added by another run
+// meanwhile may be lost.
+//
+// Parameters:
+//   - opts: whether to anonymize rather than delete, and whether to only
+//     count the records; opts.Before is left to match.
+//   - match: reports whether the record of a user made at a time is removed.
+//
+// Returns:
+//   - pruneResult: the number of records removed, or to be with opts.DryRun.
+//   - error: an error if the records cannot be read or written.
+func (s fileStorage) removeRecords(ctx context.Context, opts pruneOptions, match func(user string, t time.Time) bool) (pruneResult, error) {
+	var result pruneResult
+	dir, err := stateDir()
+	if err != nil {
+		return result, err
+	}
+
+	entries, err := s.loadHistory(ctx, "")
+	if err != nil {
+		return result, err
+	}
+	entries, result.Attempts = pruneRecords(entries, opts,
+		func(e historyEntry) bool { return match(e.User, e.Time) },
+		func(e *historyEntry) { e.User = "" })
+
+	reports, err := s.loadReports(ctx, "")
+	if err != nil {
+		return result, err
+	}
+	reports, result.Reports = pruneRecords(reports, opts,
+		func(r questionReport) bool { return match(r.User, r.Time) },
+		func(r *questionReport) { r.User = "" })
+
+	resets, err := loadAllMasteryResets()
+	if err != nil {
+		return result, err
+	}
+	for path, all := range resets {
+		var n int
+		resets[path], n = pruneRecords(all, pruneOptions{}, func(r masteryReset) bool { return match(r.User, r.Time) }, nil)
+		result.Resets += n
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+	if result.Attempts > 0 {
+		path, err := historyPath()
+		if err != nil {
+			return result, err
+		}
+		if err := rewriteJSONLines(path, entries); err != nil {
+			return result, fmt.Errorf("error writing history: %w", err)
+		}
+	}
+	if result.Reports > 0 {
+		if err := rewriteJSONLines(filepath.Join(dir, reportsFileName), reports); err != nil {
+			return result, fmt.Errorf("error writing reports: %w", err)
+		}
+	}
+	for path, kept := range resets {
+		switch {
+		case len(kept) == 0:
+			err = os.Remove(path)
+		default:
+			var data []byte
+			if data, err = json.MarshalIndent(kept, "", "  "); err == nil {
+				err = os.WriteFile(path, data, 0o644)
+			}
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return result, fmt.Errorf("error writing mastery state: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// loadAllMasteryResets reads the mastery resets of every quiz from the state
+// directory, keyed by the path of the file holding them.
+func loadAllMasteryResets() (map[string][]masteryReset, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "mastery", "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing mastery state: %w", err)
+	}
+	resets := make(map[string][]masteryReset)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading mastery state: %w", err)
+		}
+		var all []masteryReset
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, fmt.Errorf("error parsing mastery state: %w", err)
+		}
+		resets[path] = all
+	}
+	return resets, nil
+}
+
+// rewriteJSONLines replaces a file with records, one JSON object per line,
+// through a temporary file so it is never left half written.
+func rewriteJSONLines[T any](path string, records []T) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	enc := json.NewEncoder(tmp)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// writePruneResult tells the user what was pruned, or would be with DryRun.
+func writePruneResult(result pruneResult, opts pruneOptions) {
+	verb, resetVerb := "Deleted", "deleted"
+	switch {
+	case opts.DryRun && opts.Anonymize:
+		verb, resetVerb = "Would anonymize", "delete"
+	case opts.DryRun:
+		verb, resetVerb = "Would delete", "delete"
+	case opts.Anonymize:
+		verb = "Anonymized"
+	}
+	fmt.Fprintf(stdout, "%s %d attempt(s) and %d report(s) from before %s, and %s %d mastery reset(s).\n",
+		verb, result.Attempts, result.Reports, opts.Before.Local().Format(time.DateOnly), resetVerb, result.Resets)
+}
+
+// runPrune implements the "prune" subcommand.
+//
+// It deletes the attempts, reports and mastery resets older than the
+// retention period from the storage in use, local or a storage server, or
+// with -anonymize keeps the attempts and reports without the user's name,
+// so statistics and calibration can still use them. Issued certificates
+// are kept, so they can still be verified.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the records cannot be
+//     pruned.
+func runPrune(ctx context.Context, args []string) error {
+	flags := newFlagSet("prune")
+	retain := flags.String("retain", "", "keep records for `period`, e.g. 90d or 12w")
+	anonymize := flags.Bool("anonymize", false, "take the user's name out of older attempts and reports instead of deleting them")
+	dryRun := flags.Bool("dry-run", false, "only count the records that would be pruned")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 || *retain == "" {
+		return usageErrorf("usage: go-quiz prune -retain period [-anonymize] [-dry-run]")
+	}
+	period, err := parseRetention(*retain)
+	if err != nil {
+		return err
+	}
+	store, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	opts := pruneOptions{Before: time.Now().Add(-period), Anonymize: *anonymize, DryRun: *dryRun}
+	result, err := store.prune(ctx, opts)
+	if err != nil {
+		return err
+	}
+	writePruneResult(result, opts)
+	return nil
+}
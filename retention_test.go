@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRetention(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "90d", want: 90 * day},
+		{value: "12w", want: 84 * day},
+		{value: "720h", want: 30 * day},
+		{value: "1h30m", want: 90 * time.Minute},
+		{value: "0d", wantErr: true},
+		{value: "-5d", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "d", wantErr: true},
+		{value: "1.5w", wantErr: true},
+		{value: "90", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseRetention(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRetention(%q) error = %v, want error %t", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseRetention(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policies for revising answers once submitted, set for a quiz with
+// -revisions and for single questions in the revisions column.
+const (
+	revisionsFree   = "free"
+	revisionsOnce   = "once"
+	revisionsLocked = "locked"
+)
+
+// validateRevisions reports an error if policy is not a revision policy.
+func validateRevisions(policy string) error {
+	switch policy {
+	case revisionsFree, revisionsOnce, revisionsLocked:
+		return nil
+	}
+	return fmt.Errorf("invalid revision policy %q: use free, once or locked", policy)
+}
+
+// revisionPolicy returns the policy for revising the answer to q: its own,
+// or else the quiz's.
+func (e *engine) revisionPolicy(q question) string {
+	if q.Revisions != "" {
+		return q.Revisions
+	}
+	if e.opts.Revisions == "" {
+		return revisionsLocked
+	}
+	return e.opts.Revisions
+}
+
+// reviseAnswers lets the user change answers after the last question, as far
+// as each question's revision policy allows: any number of times, once, or
+// not at all. A revised question is asked again, with its time limit, and the
+// time taken is added to its duration. Typing /quit instead of the revised
+// answer ends the revisions, keeping the answers as they are, and asks
+// whether to save the session (see finishQuit).
+//
+// Parameters:
+//   - responses: the responses given, in the order asked.
+//
+// Returns:
+//   - []response: the responses with any revisions made.
+func (e *engine) reviseAnswers(responses []response) []response {
+	revisable := false
+	for _, r := range responses {
+		revisable = revisable || e.revisionPolicy(r.Question) != revisionsLocked
+	}
+	if !revisable {
+		return responses
+	}
+
+	revised := make([]bool, len(responses))
+	for {
+		fmt.Fprintln(e.out, "\nYour answers:")
+		for i, r := range responses {
+			answer := displayText(strings.TrimSpace(r.Answer))
+			if e.opts.Proctor {
+				answer = hiddenAnswer
+			}
+			note := ""
+			switch {
+			case e.revisionPolicy(r.Question) == revisionsLocked:
+				note = " (locked)"
+			case e.revisionPolicy(r.Question) == revisionsOnce && revised[i]:
+				note = " (revised)"
+			}
+			line := fmt.Sprintf("  %d. %s: %s%s", i+1, displayText(r.Question.Text), answer, note)
+			fmt.Fprintln(e.out, abbreviate(line, e.columns()))
+		}
+		fmt.Fprint(e.out, "Number of an answer to change, or Enter to finish: ")
+
+		line, err := e.readLine(time.Time{}, 0)
+		if err != nil || strings.TrimSpace(line) == "" {
+			fmt.Fprintln(e.out)
+			return responses
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(line))
+		if err != nil || n < 1 || n > len(responses) {
+			fmt.Fprintf(e.out, "Enter a number from 1 to %d.\n", len(responses))
+			continue
+		}
+
+		i := n - 1
+		switch policy := e.revisionPolicy(responses[i].Question); {
+		case policy == revisionsLocked:
+			fmt.Fprintf(e.out, "Answer %d is locked.\n", n)
+			continue
+		case policy == revisionsOnce && revised[i]:
+			fmt.Fprintf(e.out, "Answer %d has already been revised.\n", n)
+			continue
+		}
+
+		start := e.clock.Now()
+		answer, err := e.ask(responses[i].Question)
+		if errors.Is(err, errQuit) {
+			// The answer being revised stands, as do all the others.
+			e.notes, e.hinted = nil, false
+			e.finishQuit(len(responses), len(responses))
+			return responses
+		}
+		if err != nil {
+			fmt.Fprintf(e.errOut, "Error recording answer: %v\n", err)
+			continue
+		}
+		responses[i].Answer = answer
+		responses[i].Duration += e.clock.Now().Sub(start)
+		responses[i].Notes = append(responses[i].Notes, e.notes...)
+		responses[i].HintPenalty = max(responses[i].HintPenalty, e.hintPenalty())
+		e.notes, e.hinted = nil, false
+		revised[i] = true
+		if err := e.gradeResponse(&responses[i]); err != nil {
+			fmt.Fprintf(e.errOut, "Error grading answer: %v\n", err)
+			return responses
+		}
+	}
+}
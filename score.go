@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// response is the user's answer to a single question.
+type response struct {
+	Question question
+	Answer   string
+	// Duration is the time from showing the question to the answer being submitted.
+	Duration time.Duration
+	// Explanation is the reason the user gave for the answer, with -explain.
+	Explanation string
+	// Notes are the notes taken with /note while answering.
+	Notes []string
+	// HintPenalty is the share of the question's credit lost for using /hint.
+	HintPenalty float64
+	// Graded, if set, is the share of a point the response earns as graded
+	// elsewhere: by the host of a duel, whose players are sent the questions
+	// without their answers (see engine.grade).
+	Graded *float64
+}
+
+// summary is the outcome of a finished quiz as reported to the user.
+type summary struct {
+	Correct int
+	Total   int
+	// Points is the number of points earned, which includes partial credit.
+	Points float64
+	// Score is the percentage of points earned out of the total.
+	Score float64
+	// Categories is set only for quizzes with categories.
+	Categories []categoryScore
+	// Sections is set only for quizzes with sections.
+	Sections []categoryScore
+	// Quizzes is set only for sessions mixing several quizzes.
+	Quizzes   []categoryScore
+	Responses []response
+	// IRT reports an ability estimate alongside the score, given as Ability
+	// unless no question has a calibrated difficulty.
+	IRT     bool
+	Ability *abilityEstimate
+	// Streaks is the score counting answer streaks, with -streaks.
+	Streaks *streakScore
+	// PassMark is the score needed to pass, in percent; 0 means there is none.
+	PassMark float64
+}
+
+// summarize scores the responses of a finished quiz.
+//
+// Parameters:
+//   - responses: the answered questions.
+//   - total: the number of questions asked, including unanswered ones.
+//   - byCategory: whether to break the score down per category.
+func summarize(responses []response, total int, byCategory bool) summary {
+	s := summary{
+		Correct:   calculateScore(responses),
+		Points:    calculatePoints(responses),
+		Total:     total,
+		Responses: responses,
+	}
+	if total > 0 {
+		s.Score = s.Points / float64(total) * 100
+	}
+	if byCategory {
+		s.Categories = calculateCategoryScores(responses)
+	}
+	if slices.ContainsFunc(responses, func(r response) bool { return r.Question.Section != "" }) {
+		s.Sections = calculateGroupScores(responses, func(q question) string { return q.Section }, "Other")
+	}
+	if slices.ContainsFunc(responses, func(r response) bool { return r.Question.Quiz != "" }) {
+		s.Quizzes = calculateGroupScores(responses, func(q question) string { return displayPath(q.Quiz) }, "Other")
+	}
+	return s
+}
+
+// addAbility adds a Rasch ability estimate from the calibrated questions to the summary.
+func (s *summary) addAbility() {
+	s.IRT = true
+	s.Ability = estimateAbility(s.Responses)
+}
+
+// failed reports whether there is a pass mark and the score is below it.
+func (s summary) failed() bool {
+	return s.PassMark > 0 && s.Score < s.PassMark
+}
+
+// write prints the summary: the score, whether it passes, the ability
+// estimate, the quiz, section and category breakdowns, typing statistics and the
+// slowest questions.
+//
+// Parameters:
+//   - w: where to print the summary.
+//   - width: the output width in columns, used to shorten long questions.
+func (s summary) write(w io.Writer, width int) {
+	if partial := s.Points - float64(s.Correct); partial > 0 {
+		fmt.Fprintf(w, "You got %d correct and %.3g points of partial credit (%.1f%%)!\n", s.Correct, partial, s.Score)
+	} else {
+		fmt.Fprintf(w, "You got %d (%.1f%%) correct!\n", s.Correct, s.Score)
+	}
+
+	if s.PassMark > 0 {
+		if !s.failed() {
+			fmt.Fprintf(w, "Passed (pass mark %g%%).\n", s.PassMark)
+		} else {
+			fmt.Fprintf(w, "Not passed (pass mark %g%%).\n", s.PassMark)
+		}
+	}
+
+	if s.Ability != nil {
+		fmt.Fprintf(w, "Ability: %s\n", s.Ability)
+	} else if s.IRT {
+		fmt.Fprintln(w, "Ability: not estimated, as no question has a calibrated difficulty (see go-quiz calibrate)")
+	}
+
+	if s.Streaks != nil {
+		fmt.Fprintf(w, "Streak score: %.4g points (best streak %d).\n", s.Streaks.Points, s.Streaks.Best)
+	}
+
+	if s.Quizzes != nil {
+		fmt.Fprintf(w, "By quiz: %s\n", formatCategoryScores(s.Quizzes))
+	}
+	if s.Sections != nil {
+		fmt.Fprintf(w, "By section: %s\n", formatCategoryScores(s.Sections))
+	}
+	if s.Categories != nil {
+		fmt.Fprintf(w, "By category: %s\n", formatCategoryScores(s.Categories))
+	}
+
+	if len(s.Responses) > 0 {
+		fmt.Fprintln(w, formatTypingStats(calculateTypingStats(s.Responses)))
+		fmt.Fprintln(w, formatSlowest(slowestResponses(s.Responses, 5), width))
+	}
+}
+
+// correct reports whether the response earns full credit.
+func (r response) correct() bool {
+	return r.credit() >= 1
+}
+
+// credit returns the share of a point the response earns.
+func (r response) credit() float64 {
+	if r.Graded != nil {
+		return *r.Graded
+	}
+	return r.Question.credit(r.Answer) * (1 - r.HintPenalty)
+}
+
+// calculatePoints returns the points earned, counting partial credit.
+func calculatePoints(responses []response) float64 {
+	points := 0.0
+	for _, r := range responses {
+		points += r.credit()
+	}
+	return points
+}
+
+// calculateScore returns the number of correct responses.
+func calculateScore(responses []response) int {
+	userPoints := 0
+	for _, r := range responses {
+		if r.correct() {
+			userPoints++
+		}
+	}
+	return userPoints
+}
+
+// categoryScore holds the tally of correct answers for a single category.
+type categoryScore struct {
+	Name    string `json:"name"`
+	Correct int    `json:"correct"`
+	Total   int    `json:"total"`
+}
+
+// calculateCategoryScores tallies correct responses per category.
+//
+// Categories are returned in the order they first appear in the quiz.
+// Questions without a category are grouped under "Uncategorized".
+func calculateCategoryScores(responses []response) []categoryScore {
+	return calculateGroupScores(responses, func(q question) string { return q.Category }, "Uncategorized")
+}
+
+// calculateGroupScores tallies correct responses per group, such as a
+// category or section, in the order the groups first appear in the quiz.
+//
+// Parameters:
+//   - responses: the answered questions.
+//   - group: returns the name of a question's group.
+//   - fallback: the name of the group of questions whose group is blank.
+func calculateGroupScores(responses []response, group func(q question) string, fallback string) []categoryScore {
+	var scores []categoryScore
+	positions := make(map[string]int)
+
+	for _, r := range responses {
+		name := strings.TrimSpace(group(r.Question))
+		if name == "" {
+			name = fallback
+		}
+
+		pos, ok := positions[name]
+		if !ok {
+			pos = len(scores)
+			positions[name] = pos
+			scores = append(scores, categoryScore{Name: name})
+		}
+
+		scores[pos].Total++
+		if r.correct() {
+			scores[pos].Correct++
+		}
+	}
+	return scores
+}
+
+// formatCategoryScores renders category scores as a single summary line,
+// e.g. "Networking 4/5, Security 2/6".
+func formatCategoryScores(scores []categoryScore) string {
+	parts := make([]string, 0, len(scores))
+	for _, s := range scores {
+		parts = append(parts, fmt.Sprintf("%s %d/%d", s.Name, s.Correct, s.Total))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// typingStats summarises how quickly and how much the user typed.
+type typingStats struct {
+	AverageTime   time.Duration
+	AverageLength float64
+	// CharsPerMinute is measured over the whole response time, so it
+	// includes thinking time as well as typing time.
+	CharsPerMinute float64
+}
+
+// calculateTypingStats computes typing statistics over all responses.
+//
+// The function assumes responses is not empty.
+func calculateTypingStats(responses []response) typingStats {
+	var total time.Duration
+	chars := 0
+	for _, r := range responses {
+		total += r.Duration
+		chars += utf8.RuneCountInString(r.Answer)
+	}
+
+	stats := typingStats{
+		AverageTime:   total / time.Duration(len(responses)),
+		AverageLength: float64(chars) / float64(len(responses)),
+	}
+	if total > 0 {
+		stats.CharsPerMinute = float64(chars) / total.Minutes()
+	}
+	return stats
+}
+
+// formatTypingStats renders typing statistics as a single summary line.
+func formatTypingStats(stats typingStats) string {
+	return fmt.Sprintf("Typing: %.1fs and %.1f characters per answer on average (%.0f characters/min).",
+		stats.AverageTime.Seconds(), stats.AverageLength, stats.CharsPerMinute)
+}
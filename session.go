@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+// session is a quiz to take: the questions to ask and what becomes of the
+// outcome. Every command taking a quiz runs its session with runSession, so
+// the quiz flags work alike everywhere.
+type session struct {
+	// quiz is the quiz file the questions come from, which reports and the
+	// attempt are recorded against. If it is empty, each question's Quiz is
+	// used instead, and questions with neither are not recorded.
+	quiz string
+	// questions are asked in order. Warm-up and cool-down questions (see
+	// splitStages) are asked but not graded.
+	questions []question
+	// sections holds the settings of the quiz's sections, if any.
+	sections map[string]sectionSettings
+	// byCategory breaks the score down by category.
+	byCategory bool
+	// attempt, if set, is recorded in the history for each quiz the graded
+	// questions come from, with any fields of the command's own filled in;
+	// the score is filled in here.
+	attempt *historyEntry
+	// title names the quiz on the certificate written with -certificate
+	// when it is passed; if it is empty, no certificate is issued.
+	title string
+	// engine asks the questions; nil asks them at the terminal (see
+	// newTerminalEngine).
+	engine *engine
+}
+
+// runSession asks the questions of a session and reports the score:
+//
+//   - If the attempt is recorded, the user must not have used up the
+//     attempts allowed at any of its quizzes (see checkAttemptLimit).
+//   - With -proctor, typed input is hidden, and the examiner is handed the
+//     device to see the score and review the answers.
+//   - The score is judged against -pass, with an ability estimate for -irt
+//     and the longest streaks for -streaks.
+//   - Unless the user quits without saving, or go-quiz is interrupted
+//     with Ctrl+C, -results is written, the attempt recorded, and a
+//     certificate written with -certificate.
+//
+// Reported questions are saved in any case. The answer source and recording
+// are started first, unless the command already has (see
+// quizOptions.startInput), and the recording is stopped at the end.
+//
+// Parameters:
+//   - ctx: the context; the quiz ends early when it is done.
+//   - opts: the quiz flags.
+//   - flags: the flags given, whose names are recorded with the attempt.
+//   - s: the session to run.
+//
+// Returns:
+//   - error: an error if the session cannot be run, or as for sessionError.
+func runSession(ctx context.Context, opts *quizOptions, flags *flag.FlagSet, s session) error {
+	stopRecording, err := opts.startInput()
+	if err != nil {
+		return err
+	}
+	defer stopRecording()
+
+	if s.attempt != nil {
+		quizzes, _ := s.recordedQuizzes()
+		for _, quiz := range quizzes {
+			if err := checkAttemptLimit(ctx, quiz, opts.maxAttempts); err != nil {
+				if len(quizzes) > 1 {
+					return fmt.Errorf("%s: %w", displayPath(quiz), err)
+				}
+				return err
+			}
+		}
+	}
+
+	eng := s.engine
+	showInput := func() {}
+	if eng == nil {
+		eng = newTerminalEngine(opts.ask)
+		if opts.ask.Proctor {
+			if showInput, err = hideTypedInput(); err != nil {
+				return err
+			}
+		}
+	}
+	eng.sections = s.sections
+	responses := eng.run(ctx, s.questions)
+	showInput()
+	if ctx.Err() != nil {
+		// Interrupted with Ctrl+C: the score is shown, but not kept.
+		eng.quit, eng.discard = true, true
+	}
+	s.saveReports(ctx, eng.reports)
+
+	if opts.ask.Proctor {
+		if err := eng.awaitExaminer(); err != nil {
+			slog.Warn(err.Error())
+		}
+	}
+
+	graded := gradedResponses(responses)
+	result := summarize(graded, eng.scoredTotal(countGraded(s.questions), graded), s.byCategory)
+	result.PassMark = opts.pass
+	if opts.irt {
+		result.addAbility()
+	}
+	if opts.ask.Streaks {
+		result.addStreaks()
+	}
+	result.write(eng.out, eng.columns())
+	if opts.ask.Proctor {
+		writeReview(eng.out, responses, eng.columns())
+	}
+	sessionErr := sessionError(eng, result)
+
+	if eng.discard {
+		fmt.Fprintln(eng.out, "Session not saved.")
+	} else {
+		if opts.results != "" {
+			if err := writeResults(opts.results, responses); err != nil {
+				slog.Warn(err.Error())
+			}
+		}
+		if s.attempt != nil {
+			s.recordAttempts(ctx, eng, graded, flags)
+		}
+		if opts.certificate != "" && s.title != "" && sessionErr == nil {
+			if err := issueCertificate(opts.certificate, s.title, result.Score, opts.pass); err != nil {
+				slog.Warn(err.Error())
+			}
+		}
+	}
+
+	if err := stopRecording(); err != nil {
+		return err
+	}
+	return sessionErr
+}
+
+// saveReports saves the questions reported during the session against
+// their quiz, warning about those from no quiz file.
+func (s session) saveReports(ctx context.Context, reports []questionReport) {
+	var kept []questionReport
+	for _, r := range reports {
+		if s.quiz != "" || r.Quiz != "" {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) < len(reports) {
+		slog.Warn("reports on questions that are not from a quiz file are not saved")
+	}
+	if len(kept) == 0 {
+		return
+	}
+	if err := saveReports(ctx, s.quiz, kept); err != nil {
+		slog.Warn(err.Error())
+	}
+}
+
+// quizOf returns the quiz file a question of the session comes from, or ""
+// if it comes from none.
+func (s session) quizOf(q question) string {
+	if s.quiz != "" {
+		return s.quiz
+	}
+	return q.Quiz
+}
+
+// recordedQuizzes returns the quiz files the graded questions come from,
+// which the attempt is recorded against, in the order first asked, with the
+// number of questions asked from each.
+func (s session) recordedQuizzes() ([]string, map[string]int) {
+	var quizzes []string
+	asked := make(map[string]int)
+	for _, q := range s.questions {
+		quiz := s.quizOf(q)
+		if q.Stage != "" || quiz == "" {
+			continue
+		}
+		if asked[quiz] == 0 {
+			quizzes = append(quizzes, quiz)
+		}
+		asked[quiz]++
+	}
+	return quizzes, asked
+}
+
+// recordAttempts records the attempt at each quiz file the graded
+// questions come from, scored on the questions asked from it, comparing
+// each with the user's earlier attempts.
+func (s session) recordAttempts(ctx context.Context, eng *engine, graded []response, flags *flag.FlagSet) {
+	quizzes, asked := s.recordedQuizzes()
+	for _, quiz := range quizzes {
+		var part []response
+		for _, r := range graded {
+			if s.quizOf(r.Question) == quiz {
+				part = append(part, r)
+			}
+		}
+		total := eng.scoredTotal(asked[quiz], part)
+		if total == 0 {
+			continue
+		}
+		result := summarize(part, total, s.byCategory)
+		attempt := *s.attempt
+		attempt.Correct, attempt.Total, attempt.Score = result.Correct, result.Total, result.Score
+		attempt.Categories = result.Categories
+		attempt.Questions = newQuestionOutcomes(part)
+		attempt.Flags = flagsUsed(flags)
+		if len(quizzes) > 1 {
+			fmt.Fprintf(eng.out, "%s: ", displayPath(quiz))
+		}
+		if err := recordAttempt(ctx, eng.out, quiz, attempt); err != nil {
+			slog.Warn(err.Error())
+		}
+	}
+}
+
+// quizTitle returns the title of a quiz: the one set in its settings, or
+// else its file name without the extension.
+func quizTitle(filePath string, settings quizSettings) string {
+	if settings.Title != "" {
+		return settings.Title
+	}
+	return strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+}
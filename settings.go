@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commentChar starts a comment line at the top of a CSV or text quiz file,
+// before the header row or first question. Comment lines may hold the quiz's
+// settings (see readQuizSettings).
+const commentChar = '#'
+
+// settingFlags are the flags a quiz file can set defaults for: quiz flags and
+// the aggregate flag of stats and export-gradebook.
+var settingFlags = []string{
+	"lang", "shuffle", "direction", "mastery", "miss-decay", "limit", "confirm", "timeout", "timeout-answer", "proctor", "pass", "irt", "explain", "commands", "hint-time", "hint-penalty",
+	"max-attempts", "pace", "reveal", "large", "spelling", "revisions", "watch-focus", "streaks", "sudden-death", "aggregate",
+}
+
+// quizSettings are the settings written at the top of a quiz file.
+type quizSettings struct {
+	// Title is the quiz's name, shown in the quiz menu and when it starts.
+	Title string
+	// Sections holds the settings of each section, by name.
+	Sections map[string]sectionSettings
+	// Constraints limit how the quiz may be shuffled.
+	Constraints shuffleConstraints
+	// WarmUp and CoolDown are the numbers of the questions asked, ungraded,
+	// before and after the others.
+	WarmUp   []int
+	CoolDown []int
+	// Defaults maps flag names to the values the quiz gives them unless set on the command line.
+	Defaults map[string]string
+	// Preamble is the comment lines before the header row, exactly as written.
+	Preamble []string
+}
+
+// readQuizSettings reads the settings of a CSV quiz file from the comment
+// lines before its header row, written as "# name: value", e.g.:
+//
+//	# title: Networking final exam
+//	# timeout: 45s
+//	# shuffle: true
+//	# pass: 70
+//
+// The names are "title", the shuffle constraints "keep-first" and
+// "not-adjacent" (see shuffleConstraints), "warm-up" and "cool-down" (see
+// splitStages), and those in settingFlags.
+// Sections are set up with "instructions <section>" and "timeout <section>".
+// Other comment lines are ignored. Workbooks have no settings.
+//
+// Parameters:
+//   - filePath: the path to the quiz file.
+//
+// Returns:
+//   - quizSettings: the settings found, if any.
+//   - error: an error if the file cannot be read, or a parseError if a
+//     setting is malformed.
+func readQuizSettings(filePath string) (quizSettings, error) {
+	settings := quizSettings{Defaults: make(map[string]string), Sections: make(map[string]sectionSettings)}
+	if isWorkbook(filePath) {
+		return settings, nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return settings, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, string(commentChar)) {
+			break
+		}
+		settings.Preamble = append(settings.Preamble, line)
+
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, string(commentChar)), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if kind, section, ok := strings.Cut(strings.TrimSpace(name), " "); ok {
+			if err := settings.setSection(strings.ToLower(kind), strings.TrimSpace(section), value); err != nil {
+				return settings, asParseError(filePath, err)
+			}
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case name == "title":
+			settings.Title = value
+		case name == "keep-first":
+			if settings.Constraints.KeepFirst, err = parseKeepFirst(value); err != nil {
+				return settings, asParseError(filePath, err)
+			}
+		case name == "not-adjacent":
+			if settings.Constraints.Apart, err = parseApart(value); err != nil {
+				return settings, asParseError(filePath, err)
+			}
+		case name == stageWarmUp:
+			if settings.WarmUp, err = parseQuestionNumbers(name, value); err != nil {
+				return settings, asParseError(filePath, err)
+			}
+		case name == stageCoolDown:
+			if settings.CoolDown, err = parseQuestionNumbers(name, value); err != nil {
+				return settings, asParseError(filePath, err)
+			}
+		case slices.Contains(settingFlags, name):
+			settings.Defaults[name] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return settings, fmt.Errorf("error reading settings: %w", err)
+	}
+	return settings, nil
+}
+
+// sectionSettings are the settings of one section of a quiz.
+type sectionSettings struct {
+	// Instructions are shown before the section's first question.
+	Instructions string
+	// Timeout replaces -timeout for the section's questions; 0 keeps it.
+	Timeout time.Duration
+}
+
+// setSection records a setting of a section written as "# kind section: value",
+// where kind is "instructions" or "timeout". Other kinds are ordinary comments.
+func (s *quizSettings) setSection(kind, section, value string) error {
+	settings := s.Sections[section]
+	switch kind {
+	case "instructions":
+		settings.Instructions = value
+	case "timeout":
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			return fmt.Errorf("invalid timeout %q for section %q", value, section)
+		}
+		settings.Timeout = timeout
+	default:
+		return nil
+	}
+	s.Sections[section] = settings
+	return nil
+}
+
+// limitSettings are the settings a quiz sets as limits on whoever takes it:
+// a value given on the command line may make the limit stricter, but not
+// lift or loosen it.
+var limitSettings = []string{"max-attempts"}
+
+// apply sets the flags the quiz has defaults for, except those given on the
+// command line, which only win for limitSettings if they are stricter.
+// Settings for flags the command does not have are ignored.
+//
+// Parameters:
+//   - flags: the parsed quiz flags.
+//
+// Returns:
+//   - error: an error naming the setting if a value is not valid for its flag.
+func (s quizSettings) apply(flags *flag.FlagSet) error {
+	given := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	for _, name := range slices.Sorted(maps.Keys(s.Defaults)) {
+		if flags.Lookup(name) == nil {
+			continue
+		}
+		if given[name] {
+			if !slices.Contains(limitSettings, name) || !loosensLimit(flags.Lookup(name).Value.String(), s.Defaults[name]) {
+				continue
+			}
+			slog.Warn(fmt.Sprintf("the quiz sets %s to %s, which the command line can only lower", name, s.Defaults[name]))
+		}
+		if err := flags.Set(name, s.Defaults[name]); err != nil {
+			return fmt.Errorf("invalid value %q for the %s setting in the quiz file: %w", s.Defaults[name], name, err)
+		}
+	}
+	return nil
+}
+
+// loosensLimit reports whether a limit given on the command line is looser
+// than the one the quiz sets, 0 meaning no limit. An invalid setting counts
+// as looser, so that apply reports it.
+func loosensLimit(given, set string) bool {
+	limit, err := strconv.Atoi(set)
+	if err != nil {
+		return true
+	}
+	value, _ := strconv.Atoi(given)
+	return limit > 0 && (value <= 0 || value > limit)
+}
+
+// writePreamble writes the comment lines read from the top of a quiz file,
+// so that a rewritten quiz keeps its settings.
+func (s quizSettings) writePreamble(w io.Writer) error {
+	for _, line := range s.Preamble {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxShuffleTries is the number of orders tried in search of one that keeps
+// every group of questions apart. The last one is used if none does.
+const maxShuffleTries = 1000
+
+// shuffleConstraints limit how -shuffle may reorder a quiz. They are set in
+// the quiz file (see readQuizSettings) and refer to questions by their
+// number, counting from 1 in file order.
+type shuffleConstraints struct {
+	// KeepFirst is the number of questions at the start of the file that
+	// are always asked first, in file order.
+	KeepFirst int
+	// Apart lists groups of questions of which no two may be asked one
+	// after the other, e.g. because one gives away the other's answer.
+	Apart [][]int
+}
+
+// parseKeepFirst parses the keep-first setting, the number of leading questions that are not shuffled.
+func parseKeepFirst(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid keep-first setting %q: expected a number of questions", value)
+	}
+	return n, nil
+}
+
+// parseApart parses the not-adjacent setting: groups of question numbers
+// separated by commas, with the numbers of a group separated by spaces, e.g.
+// "4 5, 9 10 11".
+func parseApart(value string) ([][]int, error) {
+	var groups [][]int
+	for _, spec := range strings.Split(value, ",") {
+		var group []int
+		for _, field := range strings.Fields(spec) {
+			n, err := strconv.Atoi(field)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid not-adjacent setting %q: %q is not a question number", value, field)
+			}
+			group = append(group, n)
+		}
+		if len(group) < 2 {
+			return nil, fmt.Errorf("invalid not-adjacent setting %q: each group needs at least two questions", value)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// shuffleQuestions puts the questions in random order within the constraints.
+//
+// Questions with a section are only shuffled within their section, and the
+// sections keep the order in which they first appear in the file. The first
+// c.KeepFirst questions stay in place.
+//
+// Parameters:
+//   - questions: the questions in file order; shuffled in place.
+//   - c: the constraints on the order.
+func shuffleQuestions(questions []question, c shuffleConstraints) {
+	fixed := min(c.KeepFirst, len(questions))
+	rest := questions[fixed:]
+
+	// Group the remaining questions by section, in order of first appearance.
+	var sections []string
+	groups := make(map[string][]question)
+	for _, q := range rest {
+		if _, seen := groups[q.Section]; !seen {
+			sections = append(sections, q.Section)
+		}
+		groups[q.Section] = append(groups[q.Section], q)
+	}
+
+	for range maxShuffleTries {
+		i := 0
+		for _, s := range sections {
+			group := groups[s]
+			rand.Shuffle(len(group), func(a, b int) {
+				group[a], group[b] = group[b], group[a]
+			})
+			i += copy(rest[i:], group)
+		}
+		if keepsApart(questions, c.Apart) {
+			return
+		}
+	}
+}
+
+// keepsApart reports whether no two questions of the same group are asked one after the other.
+func keepsApart(questions []question, apart [][]int) bool {
+	for i := 1; i < len(questions); i++ {
+		for _, group := range apart {
+			if slices.Contains(group, questions[i-1].Number) && slices.Contains(group, questions[i].Number) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// simulationStart is the time every simulated session starts at, so runs are reproducible.
+var simulationStart = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeClock is a clock that only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// After advances the clock by d, since waiting on a fake clock takes no real time,
+// and returns a channel that has already delivered.
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.advance(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// scriptStep is one line typed by the simulated user.
+type scriptStep struct {
+	// delay is the time taken before the line is submitted.
+	delay time.Duration
+	line  string
+}
+
+// scriptSource replays a script as if it were typed by a user.
+//
+// Time passes on its fake clock as each line is typed, and a line that would
+// arrive after the timeout makes the read time out instead, with the rest of
+// its delay carried over to the next read.
+type scriptSource struct {
+	clock *fakeClock
+	steps []scriptStep
+}
+
+func (s *scriptSource) readLine(ctx context.Context, _ clock, timeout time.Duration) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if len(s.steps) == 0 {
+		return "", fmt.Errorf("no input provided")
+	}
+
+	step := &s.steps[0]
+	if timeout > 0 && step.delay >= timeout {
+		s.clock.advance(timeout)
+		step.delay -= timeout
+		return "", errTimeout
+	}
+
+	s.clock.advance(step.delay)
+	s.steps = s.steps[1:]
+	return step.line, nil
+}
+
+// parseScript reads a simulation script.
+//
+// Each line is typed as one line of input. A line may start with "+" and a
+// duration, e.g. "+2.5s Paris", to say how long the user takes to submit
+// it; other lines take defaultDelay.
+//
+// Parameters:
+//   - r: the script to read.
+//   - defaultDelay: the time taken by lines without a duration.
+//
+// Returns:
+//   - []scriptStep: the lines with their delays.
+//   - error: an error if the script cannot be read.
+func parseScript(r io.Reader, defaultDelay time.Duration) ([]scriptStep, error) {
+	var steps []scriptStep
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		step := scriptStep{delay: defaultDelay, line: scanner.Text()}
+		if rest, ok := strings.CutPrefix(step.line, "+"); ok {
+			spec, line, _ := strings.Cut(rest, " ")
+			if delay, err := time.ParseDuration(spec); err == nil {
+				step = scriptStep{delay: delay, line: line}
+			}
+		}
+		steps = append(steps, step)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading script: %w", err)
+	}
+	return steps, nil
+}
+
+// runSimulate implements the "simulate" subcommand.
+//
+// It runs a quiz with answers taken from a script and time taken from a fake
+// clock, printing exactly what a real session with the same answers and
+// timings would print. The attempt is not recorded in the history.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the quiz or script cannot be read.
+func runSimulate(ctx context.Context, args []string) error {
+	flags := newFlagSet("simulate")
+	opts := registerQuizFlags(flags)
+	scriptPath := flags.String("script", "-", "read the simulated input from `file` (- for standard input)")
+	delay := flags.Duration("delay", time.Second, "time taken by script lines without a +duration prefix")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageErrorf("usage: go-quiz simulate [-script file] [-delay duration] [quiz flags] quiz.csv")
+	}
+	if opts.load.Shuffle {
+		return fmt.Errorf("-shuffle cannot be used with simulate, whose output must be reproducible")
+	}
+	if opts.answersFrom != "" {
+		return fmt.Errorf("-answers-from cannot be used with simulate; use -script")
+	}
+	if opts.record != "" {
+		return fmt.Errorf("-record cannot be used with simulate")
+	}
+	filePath := flags.Arg(0)
+
+	settings, err := readQuizSettings(filePath)
+	if err != nil {
+		return err
+	}
+	if err := settings.apply(flags); err != nil {
+		return err
+	}
+	// The output must be reproducible, so a shuffle setting is ignored.
+	opts.load.Shuffle = false
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if opts.direction == directionMixed {
+		return fmt.Errorf("-direction mixed cannot be used with simulate, whose output must be reproducible")
+	}
+
+	var script io.Reader = os.Stdin
+	if *scriptPath != "-" {
+		file, err := os.Open(*scriptPath)
+		if err != nil {
+			return fmt.Errorf("error opening script: %w", err)
+		}
+		defer file.Close()
+		script = file
+	}
+
+	steps, err := parseScript(script, *delay)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Using filepath:", filePath)
+	if settings.Title != "" {
+		fmt.Println("Quiz:", settings.Title)
+	}
+
+	questions, headers, err := loadQuiz(filePath, opts.load, slog.Warn)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Number of records: %d\n", len(questions))
+
+	var warmUp, coolDown []question
+	if !opts.load.Indexed {
+		warmUp, questions, coolDown = splitStages(questions, settings.WarmUp, settings.CoolDown)
+	}
+	questions = applyDirection(questions, opts.direction)
+	questions = selectQuestions(questions, false, shuffleConstraints{}, opts.load.Limit)
+
+	fake := &fakeClock{now: simulationStart}
+	eng := &engine{
+		in:     &scriptSource{clock: fake, steps: steps},
+		out:    os.Stdout,
+		errOut: os.Stderr,
+		clock:  fake,
+		width:  defaultTerminalWidth,
+		opts:   opts.ask,
+	}
+	eng.speak = func(text string) error {
+		// Nothing is spoken; the transcript shows what would have been.
+		fmt.Fprintf(eng.out, "(spoken: %s)\n", text)
+		return nil
+	}
+	return runSession(ctx, opts, flags, session{
+		questions:  slices.Concat(warmUp, questions, coolDown),
+		sections:   settings.Sections,
+		byCategory: columnIndex(headers, "category") >= 0,
+		engine:     eng,
+	})
+}
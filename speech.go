@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// speechCommands lists, in order of preference, the text-to-speech commands
+// on the current platform, each with the text to speak as its last argument.
+func speechCommands(text string) [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"say", text}}
+	case "windows":
+		script := "Add-Type -AssemblyName System.Speech; " +
+			"(New-Object System.Speech.Synthesis.SpeechSynthesizer).Speak('" + strings.ReplaceAll(text, "'", "''") + "')"
+		return [][]string{{"powershell", "-NoProfile", "-Command", script}}
+	default:
+		return [][]string{{"espeak-ng", text}, {"espeak", text}, {"spd-say", "-w", text}}
+	}
+}
+
+// checkSpeech reports an error if no text-to-speech command is installed.
+func checkSpeech() error {
+	for _, command := range speechCommands("") {
+		if _, err := exec.LookPath(command[0]); err == nil {
+			return nil
+		}
+	}
+	var names []string
+	for _, command := range speechCommands("") {
+		names = append(names, command[0])
+	}
+	return fmt.Errorf("-spelling needs a text-to-speech command: install %s", strings.Join(names, " or "))
+}
+
+// speak says text aloud with the first text-to-speech command found, and
+// returns once it has been said.
+func speak(text string) error {
+	for _, command := range speechCommands(text) {
+		if _, err := exec.LookPath(command[0]); err == nil {
+			if err := exec.Command(command[0], command[1:]...).Run(); err != nil {
+				return fmt.Errorf("error speaking with %s: %w", command[0], err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no text-to-speech command found")
+}
+
+// spellingPrompt is what a spelling test says for a question: the word,
+// then the example sentence if there is one, then the word again.
+func spellingPrompt(q question) string {
+	if q.Sentence == "" {
+		return q.Answer
+	}
+	return fmt.Sprintf("%s. %s. %s.", q.Answer, q.Sentence, q.Answer)
+}
+
+// dictate speaks a spelling test question through the engine's speaker,
+// reporting rather than returning a failure so the test can go on.
+func (e *engine) dictate(q question) {
+	if e.speak == nil {
+		return
+	}
+	if err := e.speak(spellingPrompt(q)); err != nil {
+		fmt.Fprintf(e.errOut, "Warning: %v\n", err)
+	}
+}
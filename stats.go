@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+)
+
+// heatmapWeeks is the maximum number of weeks shown in the category heatmap.
+const heatmapWeeks = 12
+
+// sparklineLength is the maximum number of recent attempts shown in the score chart.
+const sparklineLength = 40
+
+// sparklineBars are the bar heights used for increasing scores.
+var sparklineBars = []rune("▁▂▃▄▅▆▇█")
+
+// heatmapShades are the cells used for increasing accuracy, one per 25% band.
+var heatmapShades = []rune{'░', '▒', '▓', '█'}
+
+// runStats implements the "stats" subcommand.
+//
+// It summarises the recorded attempts of one quiz, or of every quiz when no
+// file is given. For a single quiz, -aggregate (or the quiz's aggregate
+// setting) adds the grade of each user with their attempts combined.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the history cannot be read.
+func runStats(ctx context.Context, args []string) error {
+	flags := newFlagSet("stats")
+	aggregate := flags.String("aggregate", "", "also list each user's grade from their `best`, latest or average attempt")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 || (*aggregate != "" && flags.NArg() == 0) {
+		return usageErrorf("usage: go-quiz stats [[-aggregate how] quiz.csv]")
+	}
+
+	quiz := ""
+	if flags.NArg() == 1 {
+		abs, err := filepath.Abs(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("error expanding path: %w", err)
+		}
+		quiz = abs
+
+		// The history outlives quiz files, so a quiz that is gone has no settings.
+		if settings, err := readQuizSettings(quiz); err == nil {
+			if err := settings.apply(flags); err != nil {
+				return err
+			}
+		}
+	}
+
+	entries, err := loadHistory(ctx, quiz)
+	if err != nil {
+		return err
+	}
+
+	var grades []historyEntry
+	var counts []int
+	if *aggregate != "" {
+		if grades, counts, err = aggregatePerUser(entries, *aggregate); err != nil {
+			return err
+		}
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No attempts recorded yet.")
+		return nil
+	}
+
+	fmt.Printf("Attempts: %d\n", len(entries))
+
+	// A chart only makes sense for a single quiz; scores of different quizzes don't compare.
+	if quiz != "" {
+		fmt.Println(scoreSparkline(entries))
+	}
+
+	if *aggregate != "" {
+		fmt.Println()
+		writeGrades(os.Stdout, *aggregate, grades, counts)
+	}
+
+	if heatmap := categoryHeatmap(entries, time.Now()); heatmap != "" {
+		fmt.Println()
+		fmt.Println(heatmap)
+	}
+	return nil
+}
+
+// writeGrades lists the grade of each user, as combined by the named aggregation.
+func writeGrades(w io.Writer, aggregate string, grades []historyEntry, counts []int) {
+	fmt.Fprintf(w, "Grades (%s attempt):\n", aggregate)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, g := range grades {
+		noun := "attempts"
+		if counts[i] == 1 {
+			noun = "attempt"
+		}
+		fmt.Fprintf(tw, "  %s\t%.1f%%\t%d %s\n", g.User, g.Score, counts[i], noun)
+	}
+	tw.Flush()
+}
+
+// scoreSparkline renders the scores of the most recent attempts as a one-line chart,
+// oldest first, followed by the lowest, highest and latest score.
+//
+// The function assumes entries is not empty.
+func scoreSparkline(entries []historyEntry) string {
+	recent := entries[max(0, len(entries)-sparklineLength):]
+
+	var b strings.Builder
+	low, high := 100.0, 0.0
+	for _, e := range recent {
+		bar := min(int(e.Score/100*float64(len(sparklineBars))), len(sparklineBars)-1)
+		b.WriteRune(sparklineBars[max(bar, 0)])
+		low, high = min(low, e.Score), max(high, e.Score)
+	}
+
+	latest := recent[len(recent)-1].Score
+	return fmt.Sprintf("Scores: %s  (low %.0f%%, high %.0f%%, latest %.0f%%)", b.String(), low, high, latest)
+}
+
+// weekStart returns midnight on the Monday of the ISO week containing t.
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	year, month, day := t.AddDate(0, 0, -offset).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// categoryHeatmap renders category accuracy per week as a shaded grid.
+//
+// Each row is a category and each column is one of the last heatmapWeeks
+// weeks up to now. Darker cells mean higher accuracy and "·" marks weeks
+// without attempts in that category.
+//
+// Parameters:
+//   - entries: the recorded attempts to summarise.
+//   - now: the current time, which determines the last column.
+//
+// Returns:
+//   - string: the rendered heatmap with a legend, or an empty string if no
+//     attempt has category data.
+func categoryHeatmap(entries []historyEntry, now time.Time) string {
+	last := weekStart(now)
+	first := last.AddDate(0, 0, -7*(heatmapWeeks-1))
+
+	type tally struct{ correct, total int }
+	var categories []string
+	cells := make(map[string][]tally)
+
+	for _, e := range entries {
+		t := e.Time.In(now.Location())
+		if t.Before(first) {
+			continue
+		}
+		// Round to whole days so daylight saving changes don't shift the week.
+		days := int((weekStart(t).Sub(first) + 12*time.Hour).Hours() / 24)
+		week := days / 7
+		if week >= heatmapWeeks {
+			continue
+		}
+		for _, c := range e.Categories {
+			if _, ok := cells[c.Name]; !ok {
+				categories = append(categories, c.Name)
+				cells[c.Name] = make([]tally, heatmapWeeks)
+			}
+			cells[c.Name][week].correct += c.Correct
+			cells[c.Name][week].total += c.Total
+		}
+	}
+
+	if len(categories) == 0 {
+		return ""
+	}
+
+	width := 0
+	for _, name := range categories {
+		width = max(width, utf8.RuneCountInString(name))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Accuracy by category, weeks %s to %s:\n", first.Format("2006-01-02"), last.Format("2006-01-02"))
+	for _, name := range categories {
+		fmt.Fprintf(&b, "  %-*s ", width, name)
+		for _, cell := range cells[name] {
+			if cell.total == 0 {
+				b.WriteRune('·')
+				continue
+			}
+			accuracy := float64(cell.correct) / float64(cell.total)
+			shade := min(int(accuracy*float64(len(heatmapShades))), len(heatmapShades)-1)
+			b.WriteRune(heatmapShades[shade])
+		}
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "  %-*s %s", width, "", "· no data  ░ <25%  ▒ <50%  ▓ <75%  █ 75%+")
+	return b.String()
+}
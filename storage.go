@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables choosing where records are kept: the URL of a
+// storage server, and the token sent to it.
+const (
+	storageEnv      = "GO_QUIZ_STORAGE"
+	storageTokenEnv = "GO_QUIZ_STORAGE_TOKEN"
+)
+
+// storageTimeout bounds each request to a storage server.
+const storageTimeout = 30 * time.Second
+
+// defaultStorageAddress is the address storage-server listens on by
+// default: only this machine's, as other machines need a token to be let in.
+const defaultStorageAddress = "127.0.0.1:7800"
+
+// maxStorageRequestSize bounds the body of a request to a storage server,
+// in bytes. The largest are the outbox's records, sent all at once.
+const maxStorageRequestSize = 16 << 20
+
+// storageHeaderTimeout bounds the time a client of a storage server has to
+// send the headers of a request, so slow clients cannot tie it up.
+const storageHeaderTimeout = 10 * time.Second
+
+// storage keeps the records go-quiz builds up about its users: the history
+// of attempts, the questions reported with /report, and the mastery resets
+// of each quiz. Caches, indexes and the certificate key are not records and
+// always stay in the state directory.
+type storage interface {
+	loadHistory(ctx context.Context, quiz string) ([]historyEntry, error)
+	appendHistory(ctx context.Context, entry historyEntry) error
+	loadReports(ctx context.Context, quiz string) ([]questionReport, error)
+	appendReports(ctx context.Context, reports []questionReport) error
+	loadMasteryResets(ctx context.Context, quiz string) ([]masteryReset, error)
+	saveMasteryResets(ctx context.Context, quiz string, resets []masteryReset) error
+	// prune deletes or anonymizes the records made before a time (see
+	// runPrune).
+	prune(ctx context.Context, opts pruneOptions) (pruneResult, error)
+	// exportUser and deleteUser read and delete the records of one user
+	// (see runUser).
+	exportUser(ctx context.Context, user string) (userData, error)
+	deleteUser(ctx context.Context, user string) (pruneResult, error)
+}
+
+// fileStorage keeps records in files in the state directory (see stateDir).
+type fileStorage struct{}
+
+// openStorage returns the storage in use: a storage server if $GO_QUIZ_STORAGE
+// is set to its URL, and otherwise the state directory. The first time in a
+// run it returns a storage server, it sends the records kept in the outbox
+// while the server could not be reached.
+func openStorage(ctx context.Context) (storage, error) {
+	location := os.Getenv(storageEnv)
+	switch {
+	case location == "":
+		return fileStorage{}, nil
+	case strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://"):
+		store := httpStorage{base: strings.TrimSuffix(location, "/"), token: os.Getenv(storageTokenEnv)}
+		flushOutboxOnce.Do(func() { store.flushOutbox(ctx) })
+		return store, nil
+	}
+	return nil, fmt.Errorf("unsupported $%s %q: use the http(s) URL of a storage server, or leave it unset to use the state directory", storageEnv, location)
+}
+
+// httpStorage keeps records on a storage server (see runStorageServer).
+type httpStorage struct {
+	base string
+	// token, if set, is sent as a bearer token with every request.
+	token string
+}
+
+// do sends a request to the storage server and decodes its JSON response.
+//
+// Parameters:
+//   - ctx: the context; the request is abandoned when it is done.
+//   - method: the HTTP method.
+//   - path: the path of the records, e.g. "/history".
+//   - quiz: the quiz the records are about; empty means every quiz.
+//   - in: the request's body, encoded as JSON; nil sends none.
+//   - out: where to decode the response's body; nil ignores it.
+//
+// Returns:
+//   - error: an error if the request fails or the server refuses it,
+//     wrapping errUnreachable if the server did not answer.
+func (s httpStorage) do(ctx context.Context, method, path, quiz string, in, out any) error {
+	location := s.base + path
+	if quiz != "" {
+		location += "?" + url.Values{"quiz": {quiz}}.Encode()
+	}
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("error encoding records: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, location, body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	client := http.Client{Timeout: storageTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUnreachable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("storage server refused %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("error decoding records from storage server: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s httpStorage) loadHistory(ctx context.Context, quiz string) ([]historyEntry, error) {
+	var entries []historyEntry
+	return entries, s.do(ctx, http.MethodGet, "/history", quiz, nil, &entries)
+}
+
+// appendHistory records an attempt on the storage server, or in the outbox
+// if the server cannot be reached.
+func (s httpStorage) appendHistory(ctx context.Context, entry historyEntry) error {
+	err := s.postHistory(ctx, entry)
+	if errors.Is(err, errUnreachable) {
+		return queueRecord(outboxRecord{Attempt: &entry})
+	}
+	return err
+}
+
+func (s httpStorage) postHistory(ctx context.Context, entry historyEntry) error {
+	return s.do(ctx, http.MethodPost, "/history", "", entry, nil)
+}
+
+func (s httpStorage) loadReports(ctx context.Context, quiz string) ([]questionReport, error) {
+	var reports []questionReport
+	return reports, s.do(ctx, http.MethodGet, "/reports", quiz, nil, &reports)
+}
+
+// appendReports adds reports on the storage server, or in the outbox if the
+// server cannot be reached.
+func (s httpStorage) appendReports(ctx context.Context, reports []questionReport) error {
+	err := s.postReports(ctx, reports)
+	if errors.Is(err, errUnreachable) {
+		return queueRecord(outboxRecord{Reports: reports})
+	}
+	return err
+}
+
+func (s httpStorage) postReports(ctx context.Context, reports []questionReport) error {
+	return s.do(ctx, http.MethodPost, "/reports", "", reports, nil)
+}
+
+func (s httpStorage) loadMasteryResets(ctx context.Context, quiz string) ([]masteryReset, error) {
+	var resets []masteryReset
+	return resets, s.do(ctx, http.MethodGet, "/mastery", quiz, nil, &resets)
+}
+
+func (s httpStorage) saveMasteryResets(ctx context.Context, quiz string, resets []masteryReset) error {
+	return s.do(ctx, http.MethodPut, "/mastery", quiz, resets, nil)
+}
+
+func (s httpStorage) prune(ctx context.Context, opts pruneOptions) (pruneResult, error) {
+	var result pruneResult
+	return result, s.do(ctx, http.MethodPost, "/prune", "", opts, &result)
+}
+
+func (s httpStorage) exportUser(ctx context.Context, user string) (userData, error) {
+	var data userData
+	return data, s.do(ctx, http.MethodGet, "/users/"+url.PathEscape(user), "", nil, &data)
+}
+
+func (s httpStorage) deleteUser(ctx context.Context, user string) (pruneResult, error) {
+	var result pruneResult
+	return result, s.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(user), "", nil, &result)
+}
+
+// errBadRecords is the error of a request to a storage server whose
+// records cannot be decoded.
+var errBadRecords = errors.New("malformed records")
+
+// storageHandler serves a storage over HTTP, for httpStorage:
+//
+//	GET  /history?quiz=...  the attempts on a quiz, or on every quiz
+//	POST /history           records an attempt
+//	GET  /reports?quiz=...  the reports on a quiz, or on every quiz
+//	POST /reports           adds reports
+//	GET  /mastery?quiz=...  the mastery resets of a quiz
+//	PUT  /mastery?quiz=...  replaces the mastery resets of a quiz
+//	POST /prune             deletes or anonymizes older records
+//	GET  /users/{user}      the attempts, reports and mastery resets of a user
+//	DELETE /users/{user}    deletes the records of a user
+//
+// Records are sent and received as JSON, in bodies of at most
+// maxStorageRequestSize bytes. With a token, requests must carry it as a
+// bearer token. Requests are served holding mu, as files are
+// appended to and replaced whole; whatever else changes store must hold it
+// too.
+func storageHandler(store storage, token string, mu *sync.Mutex) http.Handler {
+	mux := http.NewServeMux()
+	handle := func(pattern string, serve func(r *http.Request, quiz string) (any, error)) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			want := "Bearer " + token
+			if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+				http.Error(w, "missing or wrong token", http.StatusUnauthorized)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxStorageRequestSize)
+			mu.Lock()
+			out, err := serve(r, r.URL.Query().Get("quiz"))
+			mu.Unlock()
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				status := http.StatusInternalServerError
+				switch {
+				case errors.As(err, &tooLarge):
+					status = http.StatusRequestEntityTooLarge
+				case errors.Is(err, errBadRecords):
+					status = http.StatusBadRequest
+				}
+				http.Error(w, err.Error(), status)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(out); err != nil {
+				// The status is sent, so the client sees a truncated body.
+				slog.Warn(fmt.Sprintf("error sending records: %v", err), "path", r.URL.Path)
+			}
+		})
+	}
+	decode := func(r *http.Request, v any) error {
+		if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+			return fmt.Errorf("%w: %w", errBadRecords, err)
+		}
+		return nil
+	}
+
+	handle("GET /history", func(r *http.Request, quiz string) (any, error) {
+		entries, err := store.loadHistory(r.Context(), quiz)
+		return nonNil(entries), err
+	})
+	handle("POST /history", func(r *http.Request, _ string) (any, error) {
+		var entry historyEntry
+		if err := decode(r, &entry); err != nil {
+			return nil, err
+		}
+		return struct{}{}, store.appendHistory(r.Context(), entry)
+	})
+	handle("GET /reports", func(r *http.Request, quiz string) (any, error) {
+		reports, err := store.loadReports(r.Context(), quiz)
+		return nonNil(reports), err
+	})
+	handle("POST /reports", func(r *http.Request, _ string) (any, error) {
+		var reports []questionReport
+		if err := decode(r, &reports); err != nil {
+			return nil, err
+		}
+		return struct{}{}, store.appendReports(r.Context(), reports)
+	})
+	handle("GET /mastery", func(r *http.Request, quiz string) (any, error) {
+		resets, err := store.loadMasteryResets(r.Context(), quiz)
+		return nonNil(resets), err
+	})
+	handle("PUT /mastery", func(r *http.Request, quiz string) (any, error) {
+		var resets []masteryReset
+		if err := decode(r, &resets); err != nil {
+			return nil, err
+		}
+		if quiz == "" {
+			return nil, fmt.Errorf("%w: no quiz given", errBadRecords)
+		}
+		return struct{}{}, store.saveMasteryResets(r.Context(), quiz, resets)
+	})
+	handle("POST /prune", func(r *http.Request, _ string) (any, error) {
+		var opts pruneOptions
+		if err := decode(r, &opts); err != nil {
+			return nil, err
+		}
+		return store.prune(r.Context(), opts)
+	})
+	handle("GET /users/{user}", func(r *http.Request, _ string) (any, error) {
+		return store.exportUser(r.Context(), r.PathValue("user"))
+	})
+	handle("DELETE /users/{user}", func(r *http.Request, _ string) (any, error) {
+		return store.deleteUser(r.Context(), r.PathValue("user"))
+	})
+	return mux
+}
+
+// isLoopback reports whether a server listening on addr can only be reached
+// from this machine: addr's host is a loopback address or localhost. An
+// empty host listens on every address.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// pruneDaily prunes the records in store older than period now and then
+// once a day, holding mu while it does, until ctx is done.
+func pruneDaily(ctx context.Context, store storage, mu *sync.Mutex, period time.Duration, anonymize bool) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+	for {
+		opts := pruneOptions{Before: time.Now().Add(-period), Anonymize: anonymize}
+		mu.Lock()
+		result, err := store.prune(ctx, opts)
+		mu.Unlock()
+		if err != nil {
+			slog.Error(err.Error())
+		} else {
+			slog.Info("records pruned", "before", opts.Before, "anonymize", anonymize,
+				"attempts", result.Attempts, "reports", result.Reports, "resets", result.Resets)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// nonNil returns s, or an empty slice if s is nil, so it is sent as [] rather
+// than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// runStorageServer implements the "storage-server" subcommand.
+//
+// It shares the records in its state directory with the go-quiz runs that
+// set $GO_QUIZ_STORAGE to its URL, so that the history, reports and mastery
+// of several machines are kept in one place. If $GO_QUIZ_STORAGE_TOKEN is
+// set, requests must carry the same token; if not, the server only listens
+// on a loopback address, which other machines cannot reach.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid or the server fails.
+func runStorageServer(ctx context.Context, args []string) error {
+	flags := newFlagSet("storage-server")
+	listen := flags.String("listen", defaultStorageAddress, "serve on `address`")
+	retain := flags.String("retain", "", "prune records older than `period`, e.g. 90d, at start and daily")
+	anonymize := flags.Bool("anonymize", false, "with -retain, anonymize older attempts and reports instead of deleting them")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return usageErrorf("usage: go-quiz storage-server [-listen address] [-retain period [-anonymize]]")
+	}
+	var period time.Duration
+	if *retain != "" {
+		var err error
+		if period, err = parseRetention(*retain); err != nil {
+			return err
+		}
+	}
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+	token := os.Getenv(storageTokenEnv)
+	if token == "" && !isLoopback(*listen) {
+		return fmt.Errorf("refusing to serve on %s without $%s, as anyone reaching the server could read and add records: "+
+			"set it, or listen on this machine only, e.g. -listen %s", *listen, storageTokenEnv, defaultStorageAddress)
+	}
+
+	var mu sync.Mutex
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           storageHandler(fileStorage{}, token, &mu),
+		ReadHeaderTimeout: storageHeaderTimeout,
+		ReadTimeout:       storageTimeout,
+	}
+	stop := context.AfterFunc(ctx, func() { server.Close() })
+	defer stop()
+	if period > 0 {
+		go pruneDaily(ctx, fileStorage{}, &mu, period, *anonymize)
+	}
+	fmt.Fprintf(stdout, "Serving the records in %s on %s\n", dir, *listen)
+	if err := server.ListenAndServe(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("error serving records: %w", err)
+	}
+	return nil
+}
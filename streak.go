@@ -0,0 +1,58 @@
+package main
+
+import "fmt"
+
+// Answer streaks with -streaks: each correct answer in a row raises the
+// multiplier of the points earned by streakStep, up to streakMaxMultiplier,
+// and a miss takes it back to x1.
+const (
+	streakStep          = 0.5
+	streakMaxMultiplier = 3
+)
+
+// streakMultiplier returns the multiplier of the points earned by the
+// answer making a streak of n correct answers in a row: x1 for the first,
+// x1.5 for the second, x2 for the third and so on.
+func streakMultiplier(n int) float64 {
+	return min(1+streakStep*float64(max(n-1, 0)), streakMaxMultiplier)
+}
+
+// streakScore is the score of a quiz taken with -streaks.
+type streakScore struct {
+	// Points is the sum of the points earned, each times its multiplier.
+	Points float64
+	// Best is the longest run of correct answers.
+	Best int
+}
+
+// addStreaks adds the streak score of the responses to the summary. Only
+// answers earning full credit keep a streak going; partial credit earns
+// its points at x1 and ends the streak.
+func (s *summary) addStreaks() {
+	score := &streakScore{}
+	streak := 0
+	for _, r := range s.Responses {
+		if !r.correct() {
+			streak = 0
+			score.Points += r.credit()
+			continue
+		}
+		streak++
+		score.Best = max(score.Best, streak)
+		score.Points += streakMultiplier(streak)
+	}
+	s.Streaks = score
+}
+
+// showStreak tells the user, after each answer to a graded question, whether
+// it was correct and the multiplier their streak has reached. Warm-up and
+// cool-down answers are left out, as they are from the final streaks.
+func (e *engine) showStreak(r response) {
+	if !r.correct() {
+		fmt.Fprintln(e.out, "Missed: streak back to x1.")
+		e.streak = 0
+		return
+	}
+	e.streak++
+	fmt.Fprintf(e.out, "Correct: %d in a row, x%g\n", e.streak, streakMultiplier(e.streak))
+}
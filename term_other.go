@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || freebsd)
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// ttySize returns zeros as the terminal size cannot be queried on this platform.
+func ttySize(f *os.File) (int, int) {
+	return 0, 0
+}
+
+// setEcho fails as typed input cannot be hidden on this platform.
+func setEcho(f *os.File, on bool) error {
+	return errors.New("hiding typed input is not supported on this platform")
+}
@@ -0,0 +1,35 @@
+//go:build linux || darwin || freebsd
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+// ttySize returns the width and height of the terminal attached to f,
+// or zeros if f is not a terminal.
+func ttySize(f *os.File) (int, int) {
+	var ws struct {
+		Row, Col, Xpixel, Ypixel uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0, 0
+	}
+	return int(ws.Col), int(ws.Row)
+}
+
+// setEcho turns the echoing of typed input on or off for the terminal attached to f.
+// It runs stty, which knows each platform's terminal settings.
+func setEcho(f *os.File, on bool) error {
+	mode := "-echo"
+	if on {
+		mode = "echo"
+	}
+	cmd := exec.Command("stty", mode)
+	cmd.Stdin = f
+	return cmd.Run()
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// defaultTerminalWidth is used when the terminal width cannot be determined.
+const defaultTerminalWidth = 80
+
+// bidiControls lists the Unicode directionality marks, embeddings, overrides and isolates.
+// They are invisible, but keyboards and copy-paste for right-to-left scripts insert them freely.
+const bidiControls = "\u061c\u200e\u200f\u202a\u202b\u202c\u202d\u202e\u2066\u2067\u2068\u2069"
+
+// stripBidiControls removes all directionality control characters from s.
+func stripBidiControls(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(bidiControls, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// isRTL reports whether the first strongly directional letter in s belongs to a
+// right-to-left script such as Hebrew or Arabic.
+func isRTL(s string) bool {
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko):
+			return true
+		case unicode.IsLetter(r):
+			return false
+		}
+	}
+	return false
+}
+
+// displayText prepares question text for printing.
+//
+// Right-to-left text is wrapped in a right-to-left isolate so that bidi-aware
+// terminals lay it out right to left whatever surrounds it. Punctuation that
+// belongs to the text must go inside the isolate, as displayQuestion does.
+func displayText(s string) string {
+	if isRTL(s) {
+		return "\u2067" + s + "\u2069"
+	}
+	return s
+}
+
+// displayQuestion prepares question text for printing, followed by a
+// question mark. The mark is placed inside the right-to-left isolate of
+// right-to-left text, so it ends up on the left, where the sentence ends.
+func displayQuestion(s string) string {
+	return displayText(s + "?")
+}
+
+// terminalWidth returns the number of columns available on standard output.
+//
+// It asks the terminal first, then falls back to $COLUMNS, and finally to
+// defaultTerminalWidth when output is not a terminal.
+func terminalWidth() int {
+	if width, _ := ttySize(os.Stdout); width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return defaultTerminalWidth
+}
+
+// runeWidth returns the number of terminal columns a rune occupies.
+//
+// East Asian wide characters take two columns and directionality controls
+// take none; everything else is assumed to take one.
+func runeWidth(r rune) int {
+	switch {
+	case strings.ContainsRune(bidiControls, r):
+		return 0
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+		return 2
+	}
+	return 1
+}
+
+// displayWidth returns the number of terminal columns s occupies.
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// wrapText word-wraps text to fit within width columns.
+//
+// Existing line breaks are kept. Continuation lines are prefixed with indent,
+// which gives a hanging indent when the first line starts with a label such
+// as "a) ". Words longer than a line are broken at the line end.
+//
+// Parameters:
+//   - text: the text to wrap.
+//   - width: the number of columns available.
+//   - indent: the prefix for every wrapped continuation line.
+//
+// Returns:
+//   - string: the wrapped text, without a trailing newline.
+func wrapText(text string, width int, indent string) string {
+	indentWidth := displayWidth(indent)
+	if width <= indentWidth+1 {
+		return text
+	}
+
+	var b strings.Builder
+	for i, paragraph := range strings.Split(text, "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+
+		col := 0
+		for j, word := range strings.Fields(paragraph) {
+			wordWidth := displayWidth(word)
+			if j > 0 {
+				if col+1+wordWidth <= width {
+					b.WriteByte(' ')
+					col++
+				} else {
+					b.WriteString("\n" + indent)
+					col = indentWidth
+				}
+			}
+
+			for col+wordWidth > width {
+				// Break words that can't fit on a line of their own.
+				n, cut := 0, 0
+				for k, r := range word {
+					if col+n+runeWidth(r) > width {
+						cut = k
+						break
+					}
+					n += runeWidth(r)
+				}
+				if cut == 0 {
+					break
+				}
+				b.WriteString(word[:cut] + "\n" + indent)
+				word = word[cut:]
+				wordWidth = displayWidth(word)
+				col = indentWidth
+			}
+
+			b.WriteString(word)
+			col += wordWidth
+		}
+	}
+	return b.String()
+}
+
+// abbreviate returns the first line of s, shortened with an ellipsis to at most width columns.
+// It is used where a question is referred to in a list rather than asked.
+func abbreviate(s string, width int) string {
+	line, _, more := strings.Cut(s, "\n")
+	if !more && displayWidth(line) <= width {
+		return line
+	}
+
+	var b strings.Builder
+	col := 0
+	for _, r := range line {
+		if col+runeWidth(r) > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		col += runeWidth(r)
+	}
+	return b.String() + "…"
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tournamentPlayer is a player who joined a tournament.
+type tournamentPlayer struct {
+	name string
+	d    *duelConn
+}
+
+// notice sends the player text to show. A player who has left is caught
+// when their next match starts.
+func (p *tournamentPlayer) notice(format string, args ...any) {
+	p.d.send(duelMessage{Type: duelNotice, Text: fmt.Sprintf(format, args...)})
+}
+
+// end sends the player their closing words and hangs up.
+func (p *tournamentPlayer) end(format string, args ...any) {
+	p.d.send(duelMessage{Type: duelEnd, Text: fmt.Sprintf(format, args...)})
+	p.d.conn.Close()
+}
+
+// acceptPlayers waits for players to join a game of several players until
+// there are enough. Players joining under a name already taken are turned
+// away.
+//
+// Parameters:
+//   - ctx: the context of the game; it also ends the wait for players.
+//   - listener: the listener players join on.
+//   - count: the number of players to wait for.
+//   - game: what the players join, as told to them, e.g. "tournament".
+//
+// Returns:
+//   - []*tournamentPlayer: the players, in the order they joined.
+//   - error: an error if the listener fails.
+func acceptPlayers(ctx context.Context, listener net.Listener, count int, game string) ([]*tournamentPlayer, error) {
+	var players []*tournamentPlayer
+	taken := make(map[string]bool)
+	for len(players) < count {
+		conn, err := accept(ctx, listener)
+		if err != nil {
+			return nil, fmt.Errorf("error accepting player: %w", err)
+		}
+		d := newDuelConn(ctx, conn, nil)
+		hello, err := d.receiveHello(ctx)
+		if err != nil {
+			slog.Warn(fmt.Sprintf("a player failed to join: %v", err))
+			conn.Close()
+			continue
+		}
+		if taken[hello.Name] {
+			d.send(duelMessage{Type: duelError, Text: fmt.Sprintf("%s has already joined: join with another -name", hello.Name)})
+			conn.Close()
+			continue
+		}
+		taken[hello.Name] = true
+		p := &tournamentPlayer{name: hello.Name, d: d}
+		players = append(players, p)
+		fmt.Fprintf(stdout, "%s joined (%d of %d).\n", p.name, len(players), count)
+		slog.Info("player joined", "game", game, "player", p.name, "players", len(players))
+		p.notice("Joined the %s as player %d of %d: it starts when everyone is in.", game, len(players), count)
+	}
+	return players, nil
+}
+
+// playMatch runs a match of a tournament and returns the winner. The host
+// grades each player's answers (see refereePlayer), passing their progress
+// on to the other. A player who leaves during the match loses it, and a draw
+// goes to the first player, as the higher seed.
+//
+// Parameters:
+//   - a, b: the players, a being the higher seed.
+//   - questions: the questions of the match.
+//   - timeout: the time allowed per question.
+//   - handicaps: the handicaps of the players who have one.
+//
+// Returns:
+//   - *tournamentPlayer: the winner.
+//   - string: the outcome, as shown to both players.
+func playMatch(a, b *tournamentPlayer, questions []question, timeout time.Duration, handicaps map[string]handicap) (*tournamentPlayer, string) {
+	players := [2]*tournamentPlayer{a, b}
+	var scores [2]*duelMessage
+	var wg sync.WaitGroup
+	for i, p := range players {
+		opponent := players[1-i]
+		start := duelMessage{Type: duelStart, Name: opponent.name, Questions: newDuelQuestions(questions), Timeout: timeout, Handicap: handicaps[p.name]}
+		if p.d.send(start) != nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scores[i] = refereePlayer(p.d, p.name, questions, handicaps[p.name], func(m duelMessage) { opponent.d.send(m) })
+		}()
+	}
+	wg.Wait()
+
+	i, text := decideMatch(a.name, b.name, scores)
+	winner := players[i]
+	for _, p := range players {
+		p.d.send(duelMessage{Type: duelResult, Name: winner.name, Text: text})
+	}
+	return winner, text
+}
+
+// decideMatch decides a match of a tournament from the players' scores, as
+// worked out by the host: a player who left loses, and a draw goes to the
+// first player, as the higher seed.
+//
+// Parameters:
+//   - a, b: the players' names, a being the higher seed.
+//   - scores: the players' scores, nil for a player who left.
+//
+// Returns:
+//   - int: the winner, 0 for a and 1 for b.
+//   - string: the outcome, as shown to both players.
+func decideMatch(a, b string, scores [2]*duelMessage) (int, string) {
+	switch {
+	case scores[0] == nil && scores[1] == nil:
+		return 0, fmt.Sprintf("%s and %s both left the match.", a, b)
+	case scores[0] == nil:
+		return 1, fmt.Sprintf("%s wins: %s left the match.", b, a)
+	case scores[1] == nil:
+		return 0, fmt.Sprintf("%s wins: %s left the match.", a, b)
+	}
+	first, second := *scores[0], *scores[1]
+	first.Name, second.Name = a, b
+	switch name, text := decideDuel(first, second); name {
+	case b:
+		return 1, text
+	case "":
+		return 0, text + fmt.Sprintf(" %s goes through as the higher seed.", a)
+	default:
+		return 0, text
+	}
+}
+
+// drawBye draws the player of a round with an odd number of players who goes
+// through without playing, and moves them last, where runTournament leaves
+// them out of the draw. Players who have had fewer byes are drawn first, so
+// no one gets a second before everyone still in has had one.
+//
+// Parameters:
+//   - players: the players of the round, in the order they are drawn in.
+//   - byes: the number of byes each player has had, counted here.
+//
+// Returns:
+//   - []*tournamentPlayer: the players in the order they are drawn in.
+func drawBye(players []*tournamentPlayer, byes map[string]int) []*tournamentPlayer {
+	if len(players)%2 == 0 {
+		return players
+	}
+	fewest := byes[players[0].name]
+	for _, p := range players {
+		fewest = min(fewest, byes[p.name])
+	}
+	var candidates []int
+	for i, p := range players {
+		if byes[p.name] == fewest {
+			candidates = append(candidates, i)
+		}
+	}
+	i := candidates[rand.IntN(len(candidates))]
+	bye := players[i]
+	byes[bye.name]++
+	return append(slices.Delete(players, i, i+1), bye)
+}
+
+// roundName names a round of a tournament by the number of players left in it.
+func roundName(round, players int) string {
+	switch players {
+	case 2:
+		return "Final"
+	case 3, 4:
+		return "Semi-finals"
+	}
+	return fmt.Sprintf("Round %d", round)
+}
+
+// runTournament implements the "tournament" subcommand.
+//
+// It hosts a knockout tournament for players joining with go-quiz duel -join.
+// Once everyone is in, they are drawn into pairs at random; each pair plays a
+// duel (see runDuel), and the winners go through to the next round, until one
+// is left. The rounds take their questions from the quizzes given in turn,
+// all matches of a round getting the same questions. With an odd number of
+// players in a round, one goes through without playing, drawn among those
+// who have not yet had a bye (see drawBye).
+//
+// The host only runs the tournament, grading the answers and showing the draw
+// and the results; the quizzes' own settings are not applied, as they may
+// conflict.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, a quiz cannot be
+//     loaded or the players cannot join; ctx's error if the tournament is
+//     interrupted, with no winner announced.
+func runTournament(ctx context.Context, args []string) error {
+	flags := newFlagSet("tournament")
+	opts := registerQuizFlags(flags)
+	listen := flags.String("listen", defaultDuelAddress, "host the tournament on `address`")
+	count := flags.Int("players", 4, "number of players to wait for")
+	handicapList := flags.String("handicap", "", "give players `handicaps`: point multipliers, extra time per question or both, e.g. alice=x1.5,bob=+10s")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() < 1 || *count < 2 {
+		return usageErrorf("usage: go-quiz tournament [-players n] [-listen address] [quiz flags] quiz.csv...")
+	}
+	handicaps, err := parseHandicaps(*handicapList)
+	if err != nil {
+		return err
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	var paths []string
+	for _, arg := range flags.Args() {
+		filePath, err := filepath.Abs(arg)
+		if err != nil {
+			return fmt.Errorf("error expanding path: %w", err)
+		}
+		paths = append(paths, filePath)
+	}
+	// Load every quiz now, to find errors before anyone joins.
+	loaded, err := loadQuizzes(ctx, paths, opts.load, slog.Warn)
+	if err != nil {
+		return err
+	}
+	for i, quiz := range loaded {
+		if len(quiz.questions) == 0 {
+			return fmt.Errorf("error reading %s: no questions to ask", displayPath(paths[i]))
+		}
+	}
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fmt.Errorf("error hosting tournament: %w", err)
+	}
+	writeWaiting(listener, fmt.Sprintf("%d players", *count))
+	players, err := acceptPlayers(ctx, listener, *count, "tournament")
+	listener.Close()
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, p := range players {
+		names = append(names, p.name)
+	}
+	warnUnknownHandicaps(handicaps, names)
+	rand.Shuffle(len(players), func(i, j int) { players[i], players[j] = players[j], players[i] })
+
+	byes := make(map[string]int)
+	for round := 1; len(players) > 1; round++ {
+		filePath := paths[(round-1)%len(paths)]
+		questions, err := duelQuestions(filePath, opts, shuffleConstraints{})
+		if err != nil {
+			for _, p := range players {
+				p.end("The tournament was called off: the host could not load the questions.")
+			}
+			return err
+		}
+
+		players = drawBye(players, byes)
+		name := roundName(round, len(players))
+		var draw []string
+		for i := 0; i+1 < len(players); i += 2 {
+			draw = append(draw, players[i].name+" vs "+players[i+1].name)
+		}
+		fmt.Fprintf(stdout, "\n%s (%s): %s\n", name, displayPath(filePath), strings.Join(draw, ", "))
+		for _, p := range players {
+			p.notice("\n%s: %s", name, strings.Join(draw, ", "))
+		}
+
+		winners := make([]*tournamentPlayer, (len(players)+1)/2)
+		outcomes := make([]string, len(players)/2)
+		done := make(chan struct{})
+		for i := range outcomes {
+			go func() {
+				winners[i], outcomes[i] = playMatch(players[2*i], players[2*i+1], questions, opts.ask.Timeout, handicaps)
+				done <- struct{}{}
+			}()
+		}
+		for range outcomes {
+			<-done
+		}
+		if ctx.Err() != nil {
+			// Interrupted: the matches were cut short, so no one won them.
+			slog.Info("tournament called off", "round", name)
+			for _, p := range players {
+				p.end("The tournament was called off by the host.")
+			}
+			return ctx.Err()
+		}
+		for i, text := range outcomes {
+			fmt.Fprintf(stdout, "  %s\n", text)
+			slog.Info("match played", "round", name, "winner", winners[i].name, "outcome", text)
+		}
+		if len(players)%2 == 1 {
+			bye := players[len(players)-1]
+			winners[len(winners)-1] = bye
+			fmt.Fprintf(stdout, "  %s goes through without playing.\n", bye.name)
+			bye.notice("You go through to the next round without playing.")
+		}
+
+		for i, p := range players {
+			if i/2 < len(outcomes) && winners[i/2] != p {
+				p.end("You are out of the tournament (%s).", name)
+			}
+		}
+		players = winners
+		if len(players) > 1 {
+			for _, p := range players {
+				p.notice("Through to the next round: waiting for it to start.")
+			}
+		}
+	}
+
+	fmt.Fprintf(stdout, "\n%s wins the tournament!\n", players[0].name)
+	slog.Info("tournament won", "winner", players[0].name)
+	players[0].end("You win the tournament!")
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecideMatch(t *testing.T) {
+	score := func(points float64, elapsed time.Duration) *duelMessage {
+		// The names the players give are not trusted.
+		return &duelMessage{Type: duelDone, Name: "mallory", Points: points, Elapsed: elapsed}
+	}
+	tests := []struct {
+		name   string
+		scores [2]*duelMessage
+		winner int
+		text   string
+	}{
+		{
+			name:   "more points",
+			scores: [2]*duelMessage{score(1, time.Second), score(2, 5*time.Second)},
+			winner: 1,
+			text:   "bob wins with 2 points to 1 point.",
+		},
+		{
+			name:   "faster on equal points",
+			scores: [2]*duelMessage{score(2, 3*time.Second), score(2, 2*time.Second)},
+			winner: 1,
+			text:   "bob wins on time: 2 points each, in 2s to 3s.",
+		},
+		{
+			name:   "draw goes to the higher seed",
+			scores: [2]*duelMessage{score(2, time.Second), score(2, time.Second)},
+			winner: 0,
+			text:   "Draw: 2 points each, in the same time. alice goes through as the higher seed.",
+		},
+		{
+			name:   "player who left loses",
+			scores: [2]*duelMessage{nil, score(0, time.Second)},
+			winner: 1,
+			text:   "bob wins: alice left the match.",
+		},
+		{
+			name:   "both left",
+			scores: [2]*duelMessage{nil, nil},
+			winner: 0,
+			text:   "alice and bob both left the match.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			winner, text := decideMatch("alice", "bob", tt.scores)
+			if winner != tt.winner || text != tt.text {
+				t.Errorf("decideMatch = %d, %q; want %d, %q", winner, text, tt.winner, tt.text)
+			}
+		})
+	}
+}
+
+func TestDrawBye(t *testing.T) {
+	// Five players: a bye in the first round, another among the three left
+	// in the second, and a final of two. The first player of each match
+	// wins, so a bye alone decides who reaches the final.
+	for range 100 {
+		var players []*tournamentPlayer
+		for _, name := range []string{"alice", "bob", "carol", "dave", "erin"} {
+			players = append(players, &tournamentPlayer{name: name})
+		}
+		byes := make(map[string]int)
+		var drawn []string
+		for len(players) > 1 {
+			players = drawBye(players, byes)
+			var winners []*tournamentPlayer
+			for i := 0; i+1 < len(players); i += 2 {
+				winners = append(winners, players[i])
+			}
+			if len(players)%2 == 1 {
+				bye := players[len(players)-1]
+				drawn = append(drawn, bye.name)
+				winners = append(winners, bye)
+			}
+			players = winners
+		}
+		if len(drawn) != 2 || drawn[0] == drawn[1] {
+			t.Fatalf("byes = %v, want two players with one each", drawn)
+		}
+		for name, n := range byes {
+			if n > 1 {
+				t.Fatalf("%s had %d byes, want at most 1", name, n)
+			}
+		}
+	}
+}
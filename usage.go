@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// usageReport is the anonymized summary of the recorded sessions written by
+// export-usage. It holds counts only: no user names, quiz paths, questions or
+// answers.
+type usageReport struct {
+	// Version is the go-quiz version that wrote the report.
+	Version string `json:"version"`
+	// From and To are the dates of the first and last session counted.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Sessions is the number of recorded attempts.
+	Sessions int `json:"sessions"`
+	// Users and Quizzes are the numbers of distinct users and quizzes.
+	Users   int `json:"users"`
+	Quizzes int `json:"quizzes"`
+	// QuestionsAnswered is the number of graded questions in all the sessions.
+	QuestionsAnswered int `json:"questions_answered"`
+	// AverageScore is the mean score of the sessions, as a percentage.
+	AverageScore float64 `json:"average_score"`
+	// Flags counts the sessions that set each flag, on the command line or
+	// in the quiz file. Sessions recorded before flags were tracked are not counted.
+	Flags map[string]int `json:"flags"`
+}
+
+// flagsUsed returns the names of the flags set on the command line or by the
+// quiz's settings, in alphabetical order, for the history. Their values are
+// left out, as they may name files.
+func flagsUsed(flags *flag.FlagSet) []string {
+	var names []string
+	flags.Visit(func(f *flag.Flag) {
+		names = append(names, f.Name)
+	})
+	return names
+}
+
+// summarizeUsage counts the sessions in the history from the given time on.
+//
+// Parameters:
+//   - entries: the recorded attempts, in the order they were recorded.
+//   - since: the time of the first attempt to count; the zero time counts all.
+//
+// Returns:
+//   - usageReport: the counts, without the version.
+func summarizeUsage(entries []historyEntry, since time.Time) usageReport {
+	report := usageReport{Flags: make(map[string]int)}
+	users := make(map[string]bool)
+	quizzes := make(map[string]bool)
+	var first, last time.Time
+	var scores float64
+
+	for _, e := range entries {
+		if e.Time.Before(since) {
+			continue
+		}
+		if first.IsZero() || e.Time.Before(first) {
+			first = e.Time
+		}
+		if e.Time.After(last) {
+			last = e.Time
+		}
+		report.Sessions++
+		report.QuestionsAnswered += e.Total
+		scores += e.Score
+		users[e.User] = true
+		quizzes[e.Quiz] = true
+		for _, name := range e.Flags {
+			report.Flags[name]++
+		}
+	}
+
+	report.Users, report.Quizzes = len(users), len(quizzes)
+	if report.Sessions > 0 {
+		report.From, report.To = first.Format(time.DateOnly), last.Format(time.DateOnly)
+		report.AverageScore = scores / float64(report.Sessions)
+	}
+	return report
+}
+
+// runExportUsage implements the "export-usage" subcommand.
+//
+// It summarizes how go-quiz has been used on this machine, from the history
+// alone, as a JSON file that can be collected by hand, e.g. by a team lead
+// comparing how a course is taken up. Nothing is sent anywhere, and the
+// report only holds counts, so it can be shared without revealing who took
+// which quiz or how they answered.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, the history cannot be
+//     read or the report cannot be written.
+func runExportUsage(ctx context.Context, args []string) error {
+	flags := newFlagSet("export-usage")
+	output := flags.String("o", "", "write the report to `file` instead of standard output")
+	since := flags.String("since", "", "only count sessions from `date` on, e.g. 2024-09-01")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return usageErrorf("usage: go-quiz export-usage [-since date] [-o file]")
+	}
+
+	var from time.Time
+	if *since != "" {
+		var err error
+		if from, err = time.ParseInLocation(time.DateOnly, *since, time.Local); err != nil {
+			return fmt.Errorf("invalid -since %q: use a date such as 2024-09-01", *since)
+		}
+	}
+
+	entries, err := loadHistory(ctx, "")
+	if err != nil {
+		return err
+	}
+	report := summarizeUsage(entries, from)
+	report.Version, _, _ = buildInfo()
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("error creating output: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("error writing usage report: %w", err)
+	}
+	if *output != "" {
+		notice(fmt.Sprintf("Wrote the usage of %d sessions to %s", report.Sessions, *output), "file", *output)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// userActions maps the action named after "user" to the function carrying
+// it out, given the remaining arguments.
+var userActions = map[string]func(ctx context.Context, args []string) error{
+	"export": userExport,
+	"delete": userDelete,
+}
+
+// userData is everything go-quiz keeps about a user, as written by
+// "user export".
+type userData struct {
+	User          string           `json:"user"`
+	Attempts      []historyEntry   `json:"attempts"`
+	Reports       []questionReport `json:"reports"`
+	MasteryResets []masteryReset   `json:"mastery_resets"`
+	// Certificates are always kept in the local state directory, even with
+	// a storage server.
+	Certificates []certificate `json:"certificates"`
+}
+
+// exportUser reads the attempts, reports and mastery resets of a user from
+// the state directory.
+func (s fileStorage) exportUser(ctx context.Context, user string) (userData, error) {
+	data := userData{User: user}
+	entries, err := s.loadHistory(ctx, "")
+	if err != nil {
+		return data, err
+	}
+	for _, e := range entries {
+		if e.User == user {
+			data.Attempts = append(data.Attempts, e)
+		}
+	}
+
+	reports, err := s.loadReports(ctx, "")
+	if err != nil {
+		return data, err
+	}
+	for _, r := range reports {
+		if r.User == user {
+			data.Reports = append(data.Reports, r)
+		}
+	}
+
+	resets, err := loadAllMasteryResets()
+	if err != nil {
+		return data, err
+	}
+	for _, path := range slices.Sorted(maps.Keys(resets)) {
+		for _, r := range resets[path] {
+			if r.User == user {
+				data.MasteryResets = append(data.MasteryResets, r)
+			}
+		}
+	}
+	return data, nil
+}
+
+// deleteUser deletes the attempts, reports and mastery resets of a user from
+// the state directory.
+func (s fileStorage) deleteUser(ctx context.Context, user string) (pruneResult, error) {
+	return s.removeRecords(ctx, pruneOptions{}, func(u string, _ time.Time) bool { return u == user })
+}
+
+// deleteCertificates deletes the certificates issued to a user from the
+// state directory, returning how many there were. Their codes can no longer
+// be verified.
+func deleteCertificates(user string) (int, error) {
+	certificates, err := loadCertificates()
+	if err != nil {
+		return 0, err
+	}
+	kept, deleted := pruneRecords(certificates, pruneOptions{}, func(c certificate) bool { return c.User == user }, nil)
+	if deleted == 0 {
+		return 0, nil
+	}
+	dir, err := stateDir()
+	if err != nil {
+		return 0, err
+	}
+	if err := rewriteJSONLines(filepath.Join(dir, certificatesFileName), kept); err != nil {
+		return 0, fmt.Errorf("error writing certificates: %w", err)
+	}
+	return deleted, nil
+}
+
+// runUser implements the "user" subcommand.
+//
+// It exports or deletes all the records kept about one user, for requests
+// to see or erase personal data: their attempts, reports and mastery resets
+// in the storage in use, local or a storage server, and the certificates
+// issued to them here. Both are logged, with the number of records, so
+// -log-file keeps an account of the requests handled.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name, starting with the action.
+//
+// Returns:
+//   - error: an error if the action is unknown, or as for the action.
+func runUser(ctx context.Context, args []string) error {
+	actions := slices.Sorted(maps.Keys(userActions))
+	if len(args) == 0 || userActions[args[0]] == nil {
+		return usageErrorf("usage: go-quiz user action [flags] name: actions are %s", strings.Join(actions, ", "))
+	}
+	return userActions[args[0]](ctx, args[1:])
+}
+
+// userExport implements "user export", writing the records of a user as
+// JSON.
+func userExport(ctx context.Context, args []string) error {
+	flags := newFlagSet("user export")
+	output := flags.String("o", "", "write the records to `file` instead of standard output")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || flags.Arg(0) == "" {
+		return usageErrorf("usage: go-quiz user export [-o file] name")
+	}
+	user := flags.Arg(0)
+	store, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := store.exportUser(ctx, user)
+	if err != nil {
+		return err
+	}
+	certificates, err := loadCertificates()
+	if err != nil {
+		return err
+	}
+	for _, c := range certificates {
+		if c.User == user {
+			data.Certificates = append(data.Certificates, c)
+		}
+	}
+	data.Attempts, data.Reports = nonNil(data.Attempts), nonNil(data.Reports)
+	data.MasteryResets, data.Certificates = nonNil(data.MasteryResets), nonNil(data.Certificates)
+
+	var w io.Writer = stdout
+	var file *os.File
+	if *output != "" {
+		if file, err = os.Create(*output); err != nil {
+			return fmt.Errorf("error creating export: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return fmt.Errorf("error writing export: %w", err)
+	}
+	counts := []any{"user", user, "attempts", len(data.Attempts), "reports", len(data.Reports),
+		"mastery_resets", len(data.MasteryResets), "certificates", len(data.Certificates)}
+	if file == nil {
+		slog.Info("user records exported", counts...)
+		return nil
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing export: %w", err)
+	}
+	notice(fmt.Sprintf("Wrote %d attempt(s), %d report(s), %d mastery reset(s) and %d certificate(s) of %s to %s",
+		len(data.Attempts), len(data.Reports), len(data.MasteryResets), len(data.Certificates), user, *output),
+		append(counts, "file", *output)...)
+	return nil
+}
+
+// userDelete implements "user delete", deleting the records of a user.
+func userDelete(ctx context.Context, args []string) error {
+	flags := newFlagSet("user delete")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || flags.Arg(0) == "" {
+		return usageErrorf("usage: go-quiz user delete name")
+	}
+	user := flags.Arg(0)
+	store, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := store.deleteUser(ctx, user)
+	if err != nil {
+		return err
+	}
+	certificates, err := deleteCertificates(user)
+	if err != nil {
+		return err
+	}
+	notice(fmt.Sprintf("Deleted %d attempt(s), %d report(s), %d mastery reset(s) and %d certificate(s) of %s.",
+		result.Attempts, result.Reports, result.Resets, certificates, user),
+		"user", user, "attempts", result.Attempts, "reports", result.Reports, "mastery_resets", result.Resets, "certificates", certificates)
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Build information, set at link time, e.g.
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Values left empty are filled in from the build info embedded by the Go toolchain where possible.
+var (
+	version   string
+	commit    string
+	buildDate string
+)
+
+// quizFormats lists the quiz file formats this build can read, with their versions.
+//
+// Version 1 of the CSV format is a header row naming the columns followed by
+// one question per row; the columns understood are listed in knownColumns.
+// Version 1 of the xlsx format is the same layout in a worksheet.
+// Version 1 of the txt format is blocks of Q: and A: lines (see readQA).
+var quizFormats = []string{"csv/1", "xlsx/1", "txt/1"}
+
+// buildInfo returns the version, commit and build date of the running binary.
+//
+// Values not set at link time are taken from the module version and the
+// version control information recorded by "go build"; anything still unknown
+// is reported as "unknown", or "dev" for the version.
+func buildInfo() (string, string, string) {
+	v, c, d := version, commit, buildDate
+	dirty := false
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v == "" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			v = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && c == "":
+				c = setting.Value
+			case setting.Key == "vcs.time" && d == "":
+				d = setting.Value
+			case setting.Key == "vcs.modified" && commit == "":
+				dirty = setting.Value == "true"
+			}
+		}
+	}
+
+	if v == "" {
+		v = "dev"
+	}
+	if c == "" {
+		c = "unknown"
+	} else if dirty {
+		c += "-dirty"
+	}
+	if d == "" {
+		d = "unknown"
+	}
+	return v, c, d
+}
+
+// runVersion implements the "version" subcommand.
+//
+// It prints the version, commit and build date of the binary, the Go version
+// it was built with, and the quiz file formats it supports.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if any arguments are given.
+func runVersion(ctx context.Context, args []string) error {
+	flags := newFlagSet("version")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usageErrorf("usage: go-quiz version")
+	}
+
+	v, c, d := buildInfo()
+	fmt.Printf("go-quiz %s\n", v)
+	fmt.Printf("Commit:       %s\n", c)
+	fmt.Printf("Built:        %s\n", d)
+	fmt.Printf("Go:           %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Printf("Quiz formats: %s\n", strings.Join(quizFormats, ", "))
+	return nil
+}
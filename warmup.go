@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Stages of a quiz with warm-up or cool-down questions. Only the main stage is graded.
+const (
+	stageWarmUp   = "warm-up"
+	stageCoolDown = "cool-down"
+)
+
+// parseQuestionNumbers parses the warm-up and cool-down settings: question
+// numbers separated by spaces, counting from 1 in file order, e.g. "1 2".
+func parseQuestionNumbers(name, value string) ([]int, error) {
+	var numbers []int
+	for _, field := range strings.Fields(value) {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid %s setting %q: %q is not a question number", name, value, field)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// splitStages takes the warm-up and cool-down questions out of a quiz.
+//
+// Parameters:
+//   - questions: the questions in file order.
+//   - warmUp, coolDown: the numbers of the warm-up and cool-down questions.
+//
+// Returns:
+//   - []question: the warm-up questions, in file order, marked with their stage.
+//   - []question: the graded questions, in file order.
+//   - []question: the cool-down questions, in file order, marked with their stage.
+func splitStages(questions []question, warmUp, coolDown []int) ([]question, []question, []question) {
+	var warm, graded, cool []question
+	for _, q := range questions {
+		switch {
+		case slices.Contains(warmUp, q.Number):
+			q.Stage = stageWarmUp
+			warm = append(warm, q)
+		case slices.Contains(coolDown, q.Number):
+			q.Stage = stageCoolDown
+			cool = append(cool, q)
+		default:
+			graded = append(graded, q)
+		}
+	}
+	return warm, graded, cool
+}
+
+// countGraded returns the number of graded questions, leaving out the
+// warm-up and cool-down ones.
+func countGraded(questions []question) int {
+	n := 0
+	for _, q := range questions {
+		if q.Stage == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// gradedResponses returns the responses to graded questions, leaving out the
+// warm-up and cool-down ones.
+func gradedResponses(responses []response) []response {
+	graded := make([]response, 0, len(responses))
+	for _, r := range responses {
+		if r.Question.Stage == "" {
+			graded = append(graded, r)
+		}
+	}
+	return graded
+}
+
+// introduceStage announces the start of the warm-up, the graded questions
+// or the cool-down, so it is clear which questions count.
+func (e *engine) introduceStage(stage string) {
+	switch stage {
+	case stageWarmUp:
+		fmt.Fprintln(e.out, "Warm-up (not graded):")
+	case stageCoolDown:
+		fmt.Fprintln(e.out, "\nCool-down (not graded):")
+	default:
+		fmt.Fprintln(e.out, "\nGraded questions:")
+	}
+}
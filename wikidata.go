@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// wikidataURL is the SPARQL query service of Wikidata.
+const wikidataURL = "https://query.wikidata.org/sparql"
+
+// wikidataKind is a kind of fact question generated from Wikidata.
+type wikidataKind struct {
+	// Query is a SPARQL query selecting ?subject and ?answer, with %[1]s
+	// standing for the language of the labels.
+	Query string
+	// Question is the question asked about each subject, with %s standing for it.
+	Question string
+	// Category is the category of the questions.
+	Category string
+}
+
+// wikidataKinds are the kinds of questions fetch wikidata can generate.
+var wikidataKinds = map[string]wikidataKind{
+	"capitals": {
+		Query: `SELECT ?subject ?answer WHERE {
+  ?country wdt:P31 wd:Q3624078; wdt:P36 ?capital.
+  FILTER NOT EXISTS { ?country wdt:P576 [] }
+  ?country rdfs:label ?subject. FILTER(LANG(?subject) = "%[1]s")
+  ?capital rdfs:label ?answer. FILTER(LANG(?answer) = "%[1]s")
+}`,
+		Question: "What is the capital of %s",
+		Category: "Capitals",
+	},
+	"birth-years": {
+		Query: `SELECT ?subject ?answer WHERE {
+  ?person wdt:P31 wd:Q5; wikibase:sitelinks ?links; wdt:P569 ?born.
+  FILTER(?links > 120)
+  ?person rdfs:label ?subject. FILTER(LANG(?subject) = "%[1]s")
+  BIND(STR(YEAR(?born)) AS ?answer)
+}`,
+		Question: "In what year was %s born",
+		Category: "Birth years",
+	},
+	"symbols": {
+		Query: `SELECT ?subject ?answer WHERE {
+  ?element wdt:P31 wd:Q11344; wdt:P246 ?answer.
+  ?element rdfs:label ?subject. FILTER(LANG(?subject) = "%[1]s")
+}`,
+		Question: "What is the chemical symbol of %s",
+		Category: "Chemical symbols",
+	},
+}
+
+// languageCode matches the language codes of Wikidata labels, e.g. "en" or "pt-br".
+var languageCode = regexp.MustCompile(`^[a-z]{2,3}(-[a-z]+)?$`)
+
+// wikidataResponse is the JSON returned by the Wikidata query service.
+type wikidataResponse struct {
+	Results struct {
+		Bindings []struct {
+			Subject struct{ Value string } `json:"subject"`
+			Answer  struct{ Value string } `json:"answer"`
+		} `json:"bindings"`
+	} `json:"results"`
+}
+
+// fetchWikidata implements "fetch wikidata".
+//
+// It generates fact questions, such as capitals, birth years of well-known
+// people and chemical symbols, from Wikidata, so large banks of factual
+// questions can be made without writing them by hand. Subjects with more
+// than one answer, such as countries with several capitals, are left out as
+// ambiguous.
+//
+// Parameters:
+//   - ctx: the context; the request gives up when it is done.
+//   - args: the command-line arguments following the source name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, or the questions cannot
+//     be fetched or written.
+func fetchWikidata(ctx context.Context, args []string) error {
+	kinds := slices.Sorted(maps.Keys(wikidataKinds))
+	flags := newFlagSet("fetch wikidata")
+	kind := flags.String("kind", "", "`kind` of questions: "+strings.Join(kinds, ", "))
+	count := flags.Int("count", 20, "number of questions, picked at random (0 for all)")
+	lang := flags.String("lang", "en", "`language` of the questions' subjects and answers, e.g. fr")
+	output := flags.String("o", "", "write the quiz to `file` instead of standard output")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	k, ok := wikidataKinds[*kind]
+	if flags.NArg() != 0 || !ok || *count < 0 {
+		return usageErrorf("usage: go-quiz fetch wikidata -kind %s [-count n] [-lang language] [-o file]", strings.Join(kinds, "|"))
+	}
+	if !languageCode.MatchString(*lang) {
+		return fmt.Errorf("invalid -lang %q: use a language code such as en or pt-br", *lang)
+	}
+
+	query := url.Values{"format": {"json"}, "query": {fmt.Sprintf(k.Query, *lang)}}
+	var resp wikidataResponse
+	if err := getJSON(ctx, wikidataURL+"?"+query.Encode(), &resp); err != nil {
+		return err
+	}
+
+	answers := make(map[string][]string)
+	var subjects []string
+	for _, b := range resp.Results.Bindings {
+		if _, seen := answers[b.Subject.Value]; !seen {
+			subjects = append(subjects, b.Subject.Value)
+		}
+		if !slices.Contains(answers[b.Subject.Value], b.Answer.Value) {
+			answers[b.Subject.Value] = append(answers[b.Subject.Value], b.Answer.Value)
+		}
+	}
+	subjects = slices.DeleteFunc(subjects, func(s string) bool { return len(answers[s]) != 1 })
+	if len(subjects) == 0 {
+		return fmt.Errorf("Wikidata returned no %s questions in language %q", *kind, *lang)
+	}
+
+	rand.Shuffle(len(subjects), func(i, j int) { subjects[i], subjects[j] = subjects[j], subjects[i] })
+	if *count > 0 && *count < len(subjects) {
+		subjects = subjects[:*count]
+	}
+	rows := make([][]string, 0, len(subjects))
+	for _, s := range subjects {
+		rows = append(rows, []string{fmt.Sprintf(k.Question, s), answers[s][0], k.Category})
+	}
+
+	return writeFetchedQuiz(*output, "Facts from Wikidata, wikidata.org, dedicated to the public domain (CC0)",
+		[]string{"question", "answer", "category"}, rows)
+}
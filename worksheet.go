@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// worksheetAnswerLines is the number of lines left for each written answer.
+const worksheetAnswerLines = 2
+
+// worksheet is a paper test: numbered questions with room for the answers,
+// followed by an answer key on a page of its own.
+type worksheet struct {
+	Title     string
+	Questions []question
+}
+
+// answerKey returns the expected answer to a question for the answer key, with
+// the letter of the right choice for multiple-choice questions.
+func answerKey(q question) string {
+	for i, choice := range q.Choices {
+		if choice == q.Answer {
+			return fmt.Sprintf("%c) %s", 'a'+i, q.Answer)
+		}
+	}
+	return q.Answer
+}
+
+// writeMarkdown writes the worksheet as Markdown. The answer key starts
+// after a horizontal rule.
+func (ws worksheet) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\nName: ______________________  Date: __________\n\n", ws.Title)
+	for i, q := range ws.Questions {
+		fmt.Fprintf(&b, "%d. %s?\n\n", i+1, q.Text)
+		for j, choice := range q.Choices {
+			fmt.Fprintf(&b, "   %c) %s\n", 'a'+j, choice)
+		}
+		if len(q.Choices) > 0 {
+			b.WriteString("\n   Answer: ______\n\n")
+			continue
+		}
+		for range worksheetAnswerLines {
+			b.WriteString("   ________________________________________________\n\n")
+		}
+	}
+
+	fmt.Fprintf(&b, "---\n\n## Answer key: %s\n\n", ws.Title)
+	for i, q := range ws.Questions {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, answerKey(q))
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("error writing worksheet: %w", err)
+	}
+	return nil
+}
+
+// worksheetHTML lays out a worksheet for printing from a browser, with the
+// answer key on a new page.
+var worksheetHTML = template.Must(template.New("worksheet").Funcs(template.FuncMap{
+	"letter": func(i int) string { return string(rune('a' + i)) },
+	"lines":  func() []struct{} { return make([]struct{}, worksheetAnswerLines) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 45em; margin: 2em auto; }
+li { margin-bottom: 1.5em; break-inside: avoid; }
+.line { border-bottom: 1px solid #000; height: 2em; }
+.choices { list-style: none; padding-left: 1em; }
+.choices li { margin-bottom: 0.2em; }
+.key { break-before: page; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Name: ______________________ Date: __________</p>
+<ol>
+{{- range .Questions}}
+<li>{{.Text}}?
+{{- if .Choices}}
+<ul class="choices">{{range $i, $c := .Choices}}<li>{{letter $i}}) {{$c}}</li>{{end}}</ul>
+<p>Answer: ______</p>
+{{- else}}
+{{range lines}}<div class="line"></div>{{end}}
+{{- end}}
+</li>
+{{- end}}
+</ol>
+<section class="key">
+<h2>Answer key: {{.Title}}</h2>
+<ol>
+{{- range .Keys}}
+<li>{{.}}</li>
+{{- end}}
+</ol>
+</section>
+</body>
+</html>
+`))
+
+// writeHTML writes the worksheet as an HTML page.
+func (ws worksheet) writeHTML(w io.Writer) error {
+	keys := make([]string, len(ws.Questions))
+	for i, q := range ws.Questions {
+		keys[i] = answerKey(q)
+	}
+	data := struct {
+		worksheet
+		Keys []string
+	}{ws, keys}
+	if err := worksheetHTML.Execute(w, data); err != nil {
+		return fmt.Errorf("error writing worksheet: %w", err)
+	}
+	return nil
+}
+
+// writePDF writes the worksheet as an A4 PDF document.
+func (ws worksheet) writePDF(w io.Writer) error {
+	var doc pdfDocument
+	doc.text(ws.Title, 18, true, 0, 0)
+	doc.gap(8)
+	doc.text("Name: ______________________    Date: __________", 11, false, 0, 0)
+	doc.gap(12)
+	for i, q := range ws.Questions {
+		label := fmt.Sprintf("%d. ", i+1)
+		doc.text(label+q.Text+"?", 11, false, 0, len(label))
+		for j, choice := range q.Choices {
+			doc.text(fmt.Sprintf("%c) %s", 'a'+j, choice), 11, false, len(label), 3)
+		}
+		if len(q.Choices) > 0 {
+			doc.text("Answer: ______", 11, false, len(label), 0)
+		} else {
+			for range worksheetAnswerLines {
+				doc.rule()
+			}
+		}
+		doc.gap(12)
+	}
+
+	doc.newPage()
+	doc.text("Answer key: "+ws.Title, 16, true, 0, 0)
+	doc.gap(8)
+	for i, q := range ws.Questions {
+		label := fmt.Sprintf("%d. ", i+1)
+		doc.text(label+answerKey(q), 11, false, 0, len(label))
+	}
+	return doc.writeTo(w)
+}
+
+// runWorksheet implements the "worksheet" subcommand.
+//
+// It lays out a quiz as a numbered paper test with room for the answers and
+// an answer key on a separate page, for classes without devices. The format
+// follows the extension of the -o file: .pdf, .html or .md; without -o,
+// Markdown is written to standard output.
+//
+// Parameters:
+//   - ctx: cancelled when go-quiz is interrupted.
+//   - args: the command-line arguments following the subcommand name.
+//
+// Returns:
+//   - error: an error if the arguments are invalid, the quiz cannot be loaded,
+//     or the worksheet cannot be written.
+func runWorksheet(ctx context.Context, args []string) error {
+	flags := newFlagSet("worksheet")
+	output := flags.String("o", "", "write the worksheet to `file`, a .pdf, .html or .md file (default Markdown on standard output)")
+	title := flags.String("title", "", "`title` printed at the top (default the quiz's title setting or file name)")
+	lang := flags.String("lang", "", "use the question_<lang> and answer_<lang> columns")
+	shuffle := flags.Bool("shuffle", false, "put the questions in random order")
+	limit := flags.Int("limit", 0, "include at most `n` questions (0 includes all)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageErrorf("usage: go-quiz worksheet [-o file.pdf|file.html|file.md] [-title text] [-lang language] [-shuffle] [-limit n] quiz.csv")
+	}
+	filePath := flags.Arg(0)
+
+	write := worksheet.writeMarkdown
+	switch ext := strings.ToLower(filepath.Ext(*output)); {
+	case *output == "" || ext == ".md":
+	case ext == ".html" || ext == ".htm":
+		write = worksheet.writeHTML
+	case ext == ".pdf":
+		write = worksheet.writePDF
+	default:
+		return fmt.Errorf("unsupported worksheet format %q: use .pdf, .html or .md", ext)
+	}
+
+	settings, err := readQuizSettings(filePath)
+	if err != nil {
+		return err
+	}
+	questions, _, err := loadQuiz(filePath, loadOptions{Lang: *lang}, slog.Warn)
+	if err != nil {
+		return err
+	}
+	questions = selectQuestions(questions, *shuffle, settings.Constraints, *limit)
+
+	ws := worksheet{Title: *title, Questions: questions}
+	if ws.Title == "" {
+		ws.Title = settings.Title
+	}
+	if ws.Title == "" {
+		ws.Title = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("error creating output: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := write(ws, w); err != nil {
+		return err
+	}
+	if *output != "" {
+		notice(fmt.Sprintf("Wrote a worksheet of %d questions to %s", len(questions), *output), "file", *output)
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// isWorkbook reports whether a quiz file is an Excel workbook, judging by its extension.
+func isWorkbook(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), ".xlsx")
+}
+
+// xlsxWorkbook is the part of xl/workbook.xml listing the sheets.
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		// ID is the r:id relationship attribute pointing at the sheet's part.
+		ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships is xl/_rels/workbook.xml.rels, mapping relationship IDs to parts.
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a shared or inline string: either plain text or a run of formatted pieces.
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+// xlsxSharedStrings is xl/sharedStrings.xml, the table most cell text is stored in.
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxSheet is the cell data of a worksheet part.
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string   `xml:"r,attr"`
+			T      string   `xml:"t,attr"`
+			V      string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX reads the rows of a worksheet in an Excel workbook as quiz records.
+//
+// The first non-empty row is the header row, as in a CSV file. Numbers are
+// read as Excel stores them, without the cell's display formatting, and
+// formulas as their last calculated value.
+//
+// Parameters:
+//   - filePath: the path to the .xlsx file.
+//   - sheet: the name of the worksheet to read; empty reads the first one.
+//   - columns: comma-separated column letters to read, in order, e.g. "B,D";
+//     empty reads every column.
+//   - strict: whether a row with cells beyond the header row is an error instead of being skipped.
+//
+// Returns:
+//   - [][]string: the rows after the header row, with blank rows left out.
+//   - []string: the header row.
+//   - []rowError: the rows that were skipped, numbered as in the spreadsheet.
+//   - error: an error if the workbook, sheet or columns cannot be read.
+func readXLSX(filePath, sheet, columns string, strict bool) ([][]string, []string, []rowError, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error opening workbook: %w", err)
+	}
+	defer archive.Close()
+
+	var selected []int
+	if columns != "" {
+		for _, name := range strings.Split(columns, ",") {
+			col, ok := columnNumber(strings.TrimSpace(name))
+			if !ok {
+				return nil, nil, nil, fmt.Errorf("invalid column %q: use spreadsheet letters from A to XFD, such as A or BC", name)
+			}
+			selected = append(selected, col)
+		}
+	}
+
+	part, err := sheetPart(&archive.Reader, sheet)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if err := decodeZipXML(&archive.Reader, "xl/sharedStrings.xml", &shared); err != nil && err != errZipPartMissing {
+		return nil, nil, nil, err
+	}
+
+	var data xlsxSheet
+	if err := decodeZipXML(&archive.Reader, part, &data); err != nil {
+		if err == errZipPartMissing {
+			return nil, nil, nil, fmt.Errorf("error reading workbook: missing %s", part)
+		}
+		return nil, nil, nil, err
+	}
+
+	var headers []string
+	var records [][]string
+	var skipped []rowError
+	for i, row := range data.Rows {
+		line := row.R
+		if line == 0 {
+			line = i + 1
+		}
+
+		var cells []string
+		for j, c := range row.Cells {
+			// The reference is optional; without one, cells follow each other.
+			letters := strings.TrimRight(c.R, "0123456789")
+			col, ok := columnNumber(letters)
+			if !ok && letters != "" {
+				return nil, nil, nil, fmt.Errorf("error reading cell %s: not a column from A to XFD", c.R)
+			}
+			if !ok {
+				col = j
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+
+			switch c.T {
+			case "s":
+				n, err := strconv.Atoi(c.V)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, nil, nil, fmt.Errorf("error reading cell %s: invalid shared string %q", c.R, c.V)
+				}
+				cells[col] = shared.Items[n].String()
+			case "inlineStr":
+				cells[col] = c.Inline.String()
+			case "b":
+				cells[col] = "FALSE"
+				if c.V == "1" {
+					cells[col] = "TRUE"
+				}
+			case "", "n":
+				cells[col] = formatXLSXNumber(c.V)
+			default:
+				cells[col] = c.V
+			}
+		}
+
+		if selected != nil {
+			picked := make([]string, len(selected))
+			for k, col := range selected {
+				if col < len(cells) {
+					picked[k] = cells[col]
+				}
+			}
+			cells = picked
+		}
+
+		if strings.TrimSpace(strings.Join(cells, "")) == "" {
+			continue
+		}
+		if headers == nil {
+			headers = trimTrailingBlanks(cells)
+			continue
+		}
+
+		cells = trimTrailingBlanks(cells)
+		if len(cells) > len(headers) {
+			rowErr := rowError{
+				Line:    line,
+				Reason:  fmt.Sprintf("wrong number of fields: expected %d, found %d (is there text beyond the last column?)", len(headers), len(cells)),
+				Content: abbreviate(strings.Join(cells, ","), maxRowContent),
+			}
+			if strict {
+				return nil, nil, nil, fmt.Errorf("error reading records: %w", rowErr)
+			}
+			skipped = append(skipped, rowErr)
+			continue
+		}
+		for len(cells) < len(headers) {
+			cells = append(cells, "")
+		}
+		records = append(records, cells)
+	}
+
+	if headers == nil {
+		return nil, nil, nil, fmt.Errorf("error reading headers: the sheet is empty")
+	}
+	return records, headers, skipped, nil
+}
+
+// errZipPartMissing is returned by decodeZipXML for a part the archive does not contain.
+var errZipPartMissing = errors.New("part not found")
+
+// decodeZipXML decodes the XML part called name in a zip archive into v.
+func decodeZipXML(archive *zip.Reader, name string, v any) error {
+	for _, f := range archive.File {
+		if f.Name != name {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("error reading workbook: %w", err)
+		}
+		defer r.Close()
+		if err := xml.NewDecoder(r).Decode(v); err != nil && err != io.EOF {
+			return fmt.Errorf("error reading workbook %s: %w", name, err)
+		}
+		return nil
+	}
+	return errZipPartMissing
+}
+
+// sheetPart returns the name of the zip part holding the named worksheet, or the first one if name is empty.
+func sheetPart(archive *zip.Reader, name string) (string, error) {
+	var workbook xlsxWorkbook
+	if err := decodeZipXML(archive, "xl/workbook.xml", &workbook); err != nil {
+		if err == errZipPartMissing {
+			return "", fmt.Errorf("error reading workbook: not an .xlsx file")
+		}
+		return "", err
+	}
+
+	var rels xlsxRelationships
+	if err := decodeZipXML(archive, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		if err == errZipPartMissing {
+			return "", fmt.Errorf("error reading workbook: missing sheet relationships")
+		}
+		return "", err
+	}
+
+	var names []string
+	for _, s := range workbook.Sheets {
+		names = append(names, s.Name)
+		if name != "" && s.Name != name {
+			continue
+		}
+		for _, rel := range rels.Relationships {
+			if rel.ID != s.ID {
+				continue
+			}
+			if target, ok := strings.CutPrefix(rel.Target, "/"); ok {
+				return target, nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+		return "", fmt.Errorf("error reading workbook: sheet %q has no data", s.Name)
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("error reading workbook: it has no sheets")
+	}
+	return "", fmt.Errorf("sheet %q not found; the workbook has: %s", name, strings.Join(names, ", "))
+}
+
+// maxXLSXColumns is the number of columns in a worksheet, A to XFD.
+const maxXLSXColumns = 16384
+
+// columnNumber converts spreadsheet column letters, e.g. "A" or "BC", to a
+// zero-based index. It reports false for anything but letters from A to XFD.
+func columnNumber(letters string) (int, bool) {
+	if letters == "" {
+		return 0, false
+	}
+	n := 0
+	for _, r := range strings.ToUpper(letters) {
+		if r < 'A' || r > 'Z' {
+			return 0, false
+		}
+		n = n*26 + int(r-'A') + 1
+		if n > maxXLSXColumns {
+			return 0, false
+		}
+	}
+	return n - 1, true
+}
+
+// formatXLSXNumber renders a stored number to 15 significant digits, as Excel
+// displays it, so a value stored as 0.30000000000000004 reads back as 0.3.
+func formatXLSXNumber(v string) string {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v
+	}
+	return strconv.FormatFloat(f, 'g', 15, 64)
+}
+
+// trimTrailingBlanks drops empty cells from the end of a row.
+func trimTrailingBlanks(cells []string) []string {
+	for len(cells) > 0 && strings.TrimSpace(cells[len(cells)-1]) == "" {
+		cells = cells[:len(cells)-1]
+	}
+	return cells
+}
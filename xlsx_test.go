@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestColumnNumber(t *testing.T) {
+	tests := []struct {
+		letters string
+		want    int
+		ok      bool
+	}{
+		{"A", 0, true},
+		{"z", 25, true},
+		{"AA", 26, true},
+		{"BC", 54, true},
+		{"XFD", 16383, true},
+		{"XFE", 0, false},
+		{"AAAA", 0, false},
+		{"ZZZZZZZZZZZZZZZ", 0, false},
+		{"", 0, false},
+		{"A1", 0, false},
+		{"$A", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := columnNumber(tt.letters)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("columnNumber(%q) = %d, %v; want %d, %v", tt.letters, got, ok, tt.want, tt.ok)
+		}
+	}
+}